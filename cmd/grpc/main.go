@@ -4,18 +4,25 @@ import (
 	"context"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/httpserver"
 	"github.com/vogiaan1904/payment-svc/internal/interceptors"
-	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
 	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
-	zpGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/gateways"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
 	pkgGrpc "github.com/vogiaan1904/payment-svc/pkg/grpc"
 	pkgLog "github.com/vogiaan1904/payment-svc/pkg/log"
 	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.temporal.io/sdk/client"
 	"google.golang.org/grpc"
 )
@@ -49,6 +56,18 @@ func main() {
 	defer tCli.Close()
 	l.Info(context.Background(), "Temporal Client connected.")
 
+	// MongoDB
+	mCli, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.Mongo.URI))
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to connect to MongoDB: %v", err)
+	}
+	defer mCli.Disconnect(context.Background())
+	db := mCli.Database(cfg.Mongo.Database)
+	if err := repository.EnsureIndexes(context.Background(), db); err != nil {
+		l.Fatalf(context.Background(), "failed to create MongoDB indexes: %v", err)
+	}
+	l.Info(context.Background(), "MongoDB connected.")
+
 	// gRPC clients
 	gprcClis, cleanupGrpc, err := pkgGrpc.InitGrpcClients(cfg.Grpc.OrderSvcAddr, l, cfg.Log.RedactFields)
 	if err != nil {
@@ -61,12 +80,14 @@ func main() {
 	)
 
 	// Payment gateways
-	zpGW := zpGW.New(cfg.PayGateway.Zalopay.AppID, cfg.PayGateway.Zalopay.Key1, cfg.PayGateway.Zalopay.Key2, cfg.PayGateway.Zalopay.Host)
-
 	gwf := bankTf.NewPaymentGatewayFactory()
-	gwf.RegisterGateway(models.GatewayTypeZalopay, zpGW)
+	gwHealth := bankTf.NewGatewayHealth(cfg.Breaker)
+	if err := gateways.Register(gwf, cfg, l); err != nil {
+		l.Fatalf(context.Background(), "failed to register payment gateways: %v", err)
+	}
 
-	pmtSvc := bankTf.NewPaymentService(l, gwf, gprcClis.Order, tCli)
+	pmtSvc := bankTf.NewPaymentService(l, gwf, bankTf.NewGatewayRouter(gwf, cfg.Routing.Rules, gwHealth), gwHealth, gprcClis.Order, tCli, cfg.Temporal, cfg.Outbox,
+		repository.NewPaymentRepository(db), repository.NewStatementReviewRepository(db), repository.NewCallbackOutboxRepository(db), statement.NewCSVLayout(cfg.Statement))
 	payment.RegisterPaymentServiceServer(sv, pmtSvc)
 
 	go func() {
@@ -77,6 +98,20 @@ func main() {
 		}
 	}()
 
+	// Admin endpoints: the gRPC process makes the gateway calls, so its
+	// breaker state and metrics are served here.
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", promhttp.Handler())
+	adminMux.Handle("/health", httpserver.HealthHandler(gwHealth, gwf))
+	adminSv := &http.Server{Addr: ":" + cfg.Metrics.Port, Handler: adminMux}
+
+	go func() {
+		l.Infof(context.Background(), "Admin HTTP server started on %s", adminSv.Addr)
+		if err := adminSv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			l.Errorf(context.Background(), "failed to serve admin HTTP: %v", err)
+		}
+	}()
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	<-sigCh
@@ -85,4 +120,10 @@ func main() {
 
 	sv.GracefulStop()
 	l.Info(context.Background(), "gRPC server stopped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := adminSv.Shutdown(ctx); err != nil {
+		l.Errorf(context.Background(), "admin HTTP server shutdown error: %v", err)
+	}
 }
@@ -10,10 +10,15 @@ import (
 	"github.com/vogiaan1904/payment-svc/config"
 	"github.com/vogiaan1904/payment-svc/internal/httpserver"
 	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
 	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
-	zpGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/gateways"
+	mockGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/mock"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
 	pkgGrpc "github.com/vogiaan1904/payment-svc/pkg/grpc"
 	pkgLog "github.com/vogiaan1904/payment-svc/pkg/log"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.temporal.io/sdk/client"
 )
 
@@ -40,6 +45,18 @@ func main() {
 	defer tCli.Close()
 	l.Info(context.Background(), "Temporal Client connected.")
 
+	// MongoDB
+	mCli, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.Mongo.URI))
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to connect to MongoDB: %v", err)
+	}
+	defer mCli.Disconnect(context.Background())
+	db := mCli.Database(cfg.Mongo.Database)
+	if err := repository.EnsureIndexes(context.Background(), db); err != nil {
+		l.Fatalf(context.Background(), "failed to create MongoDB indexes: %v", err)
+	}
+	l.Info(context.Background(), "MongoDB connected.")
+
 	// gRPC clients
 	grpcClients, cleanupGrpc, err := pkgGrpc.InitGrpcClients(cfg.Grpc.OrderSvcAddr, l, cfg.Log.RedactFields)
 	if err != nil {
@@ -48,14 +65,23 @@ func main() {
 	defer cleanupGrpc()
 
 	// Payment gateways
-	zpGW := zpGW.New(cfg.PayGateway.Zalopay.AppID, cfg.PayGateway.Zalopay.Key1, cfg.PayGateway.Zalopay.Key2, cfg.PayGateway.Zalopay.Host)
 	gwf := bankTf.NewPaymentGatewayFactory()
-	gwf.RegisterGateway(models.GatewayTypeZalopay, zpGW)
+	gwHealth := bankTf.NewGatewayHealth(cfg.Breaker)
+	if err := gateways.Register(gwf, cfg, l); err != nil {
+		l.Fatalf(context.Background(), "failed to register payment gateways: %v", err)
+	}
+
+	// The mock gateway's payment page and control API are served here.
+	var mGW *mockGW.MockGateway
+	if gw, err := gwf.GetGateway(models.GatewayTypeMock); err == nil {
+		mGW, _ = gw.(*mockGW.MockGateway)
+	}
 
-	pmtSvc := bankTf.NewPaymentService(l, gwf, grpcClients.Order, tCli)
+	pmtSvc := bankTf.NewPaymentService(l, gwf, bankTf.NewGatewayRouter(gwf, cfg.Routing.Rules, gwHealth), gwHealth, grpcClients.Order, tCli, cfg.Temporal, cfg.Outbox,
+		repository.NewPaymentRepository(db), repository.NewStatementReviewRepository(db), repository.NewCallbackOutboxRepository(db), statement.NewCSVLayout(cfg.Statement))
 
 	httpAddr := ":" + cfg.Http.Port
-	httpServer := httpserver.New(httpAddr, l, pmtSvc)
+	httpServer := httpserver.New(httpAddr, l, pmtSvc, gwHealth, gwf, mGW, cfg.Return)
 
 	go func() {
 		if err := httpServer.Start(); err != nil {
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/httpserver"
+	"github.com/vogiaan1904/payment-svc/internal/interceptors"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/gateways"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
+	pkgGrpc "github.com/vogiaan1904/payment-svc/pkg/grpc"
+	pkgLog "github.com/vogiaan1904/payment-svc/pkg/log"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/product"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	l := pkgLog.InitializeZapLogger(pkgLog.ZapConfig{
+		Level:    cfg.Log.Level,
+		Encoding: cfg.Log.Encoding,
+		Mode:     cfg.Log.Mode,
+	})
+
+	// Temporal client
+	tCli, err := client.Dial(client.Options{
+		HostPort:  cfg.Temporal.HostPort,
+		Namespace: cfg.Temporal.Namespace,
+	})
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to initialize Temporal client: %v", err)
+	}
+	defer tCli.Close()
+	l.Info(context.Background(), "Temporal Client connected.")
+
+	// MongoDB
+	mCli, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.Mongo.URI))
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to connect to MongoDB: %v", err)
+	}
+	defer mCli.Disconnect(context.Background())
+	db := mCli.Database(cfg.Mongo.Database)
+	if err := repository.EnsureIndexes(context.Background(), db); err != nil {
+		l.Fatalf(context.Background(), "failed to create MongoDB indexes: %v", err)
+	}
+	l.Info(context.Background(), "MongoDB connected.")
+
+	// gRPC clients
+	grpcClients, cleanupGrpc, err := pkgGrpc.InitGrpcClients(cfg.Grpc.OrderSvcAddr, l, cfg.Log.RedactFields)
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to initialize gRPC clients: %v", err)
+	}
+	defer cleanupGrpc()
+
+	productConn, err := grpc.NewClient(cfg.Grpc.ProductSvcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(interceptors.GrpcClientLoggingInterceptor(l, cfg.Log.RedactFields)),
+	)
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to create product service client: %v", err)
+	}
+	defer productConn.Close()
+
+	// Payment gateways
+	gwf := bankTf.NewPaymentGatewayFactory()
+	gwHealth := bankTf.NewGatewayHealth(cfg.Breaker)
+	if err := gateways.Register(gwf, cfg, l); err != nil {
+		l.Fatalf(context.Background(), "failed to register payment gateways: %v", err)
+	}
+
+	pmtSvc := bankTf.NewPaymentService(l, gwf, bankTf.NewGatewayRouter(gwf, cfg.Routing.Rules, gwHealth), gwHealth, grpcClients.Order, tCli, cfg.Temporal, cfg.Outbox,
+		repository.NewPaymentRepository(db), repository.NewStatementReviewRepository(db), repository.NewCallbackOutboxRepository(db), statement.NewCSVLayout(cfg.Statement))
+
+	acts, err := bankTf.NewPaymentActivities(pmtSvc, product.NewProductServiceClient(productConn))
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to create payment activities: %v", err)
+	}
+
+	w := worker.New(tCli, cfg.Temporal.PaymentTaskQueue, worker.Options{
+		WorkerStopTimeout: cfg.Temporal.WorkerStopTimeout,
+	})
+	bankTf.RegisterPaymentWorker(w, acts)
+
+	if err := w.Start(); err != nil {
+		l.Fatalf(context.Background(), "failed to start Temporal worker: %v", err)
+	}
+	l.Infof(context.Background(), "Payment worker started on task queue %s", cfg.Temporal.PaymentTaskQueue)
+
+	// Callbacks whose signal failed wait in the outbox until it goes through.
+	dispatcher, err := bankTf.NewCallbackDispatcher(pmtSvc)
+	if err != nil {
+		l.Fatalf(context.Background(), "failed to create callback dispatcher: %v", err)
+	}
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	dispatchDone := make(chan struct{})
+	go func() {
+		defer close(dispatchDone)
+		dispatcher.Run(dispatchCtx)
+	}()
+
+	// Activities call the gateways, so the worker serves its own breaker
+	// state and metrics, next to the callback outbox it drains.
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", promhttp.Handler())
+	adminMux.Handle("/health", httpserver.HealthHandler(gwHealth, gwf))
+	adminMux.Handle("GET /callbacks/outbox", httpserver.OutboxListHandler(dispatcher))
+	adminMux.Handle("POST /callbacks/outbox/{id}/redrive", httpserver.OutboxRedriveHandler(dispatcher))
+	adminSv := &http.Server{Addr: ":" + cfg.Metrics.Port, Handler: adminMux}
+
+	go func() {
+		l.Infof(context.Background(), "Admin HTTP server started on %s", adminSv.Addr)
+		if err := adminSv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			l.Errorf(context.Background(), "failed to serve admin HTTP: %v", err)
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	// Stop waits up to WorkerStopTimeout for running activities to finish;
+	// whatever is left is retried by Temporal on another worker.
+	l.Info(context.Background(), "Shutting down payment worker...")
+	stopDispatch()
+	<-dispatchDone
+	w.Stop()
+	l.Info(context.Background(), "Payment worker stopped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := adminSv.Shutdown(ctx); err != nil {
+		l.Errorf(context.Background(), "admin HTTP server shutdown error: %v", err)
+	}
+}
@@ -1,18 +1,46 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v9"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Log        LogConfig
-	PayGateway PaymentGatewayConfig
-	Grpc       GrpcMicroserviceConfig
-	Http       HttpConfig
-	Temporal   TemporalConfig
+	App         AppConfig
+	Log         LogConfig
+	PayGateway  PaymentGatewayConfig
+	Grpc        GrpcMicroserviceConfig
+	Http        HttpConfig
+	Return      ReturnConfig
+	Temporal    TemporalConfig
+	Outbox      OutboxConfig
+	Mongo       MongoConfig
+	Statement   StatementConfig
+	Routing     RoutingConfig
+	Breaker     CircuitBreakerConfig
+	Metrics     MetricsConfig
+	GatewayHTTP GatewayHTTPConfig
+}
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	// EnvSandbox is only used for gateway instances, whose provider APIs come
+	// as sandbox or production.
+	EnvSandbox = "sandbox"
+)
+
+type AppConfig struct {
+	Env string `env:"APP_ENV" envDefault:"development"`
+}
+
+func (c AppConfig) IsProduction() bool {
+	return c.Env == EnvProduction
 }
 
 type LogConfig struct {
@@ -22,30 +50,202 @@ type LogConfig struct {
 	RedactFields []string `env:"LOG_REDACT_FIELDS" envDefault:"password,token,secret"`
 }
 
+// PaymentGatewayConfig declares the gateway instances to register. GATEWAYS
+// is a JSON array of GatewayDefinition; Zalopay and Mock hold the credentials
+// of instances without a credentials reference.
 type PaymentGatewayConfig struct {
-	Zalopay ZalopayConfig
+	DefinitionsJSON string `env:"GATEWAYS" envDefault:"[{\"name\":\"zalopay\",\"type\":\"zalopay\",\"enabled\":true},{\"name\":\"cod\",\"type\":\"cod\",\"enabled\":true},{\"name\":\"mock\",\"type\":\"mock\",\"enabled\":false}]"`
+	Definitions     []GatewayDefinition
+	Zalopay         ZalopayConfig
+	Mock            MockGatewayConfig
+}
+
+// GatewayDefinition declares one gateway instance. Name is the provider key
+// clients use; Type selects the constructor. Credentials is an env prefix:
+// "ZALOPAY_B2B" reads ZALOPAY_B2B_ZALOPAY_APP_ID and so on, and an empty
+// reference reads the unprefixed variables. Environment is "sandbox" (the
+// default) or "production" and picks the provider's base URLs; Endpoints
+// override single URLs, including the callback path.
+type GatewayDefinition struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Enabled     bool              `json:"enabled"`
+	Environment string            `json:"environment"`
+	Credentials string            `json:"credentials"`
+	Endpoints   map[string]string `json:"endpoints"`
+}
+
+// LoadCredentials parses the env variables behind a credentials reference
+// into out, e.g. a ZalopayConfig.
+func LoadCredentials(ref string, out interface{}) error {
+	opts := env.Options{}
+	if ref != "" {
+		opts.Prefix = strings.ToUpper(ref) + "_"
+	}
+	return env.ParseWithOptions(out, opts)
 }
 
 type ZalopayConfig struct {
 	AppID int    `env:"ZALOPAY_APP_ID" envDefault:"1234567890"`
 	Key1  string `env:"ZALOPAY_KEY1" envDefault:"1234567890"`
 	Key2  string `env:"ZALOPAY_KEY2" envDefault:"1234567890"`
-	Host  string `env:"NGROK_TEST_URL" envDefault:""`
+	// KeysJSON replaces Key1/Key2 during a rotation: a JSON array of
+	// {"version","key1","key2","activates_at","expires_at"}. Requests are
+	// signed with the latest active version, callbacks verified with any.
+	KeysJSON    string        `env:"ZALOPAY_KEYS"`
+	Host        string        `env:"NGROK_TEST_URL" envDefault:""`
+	BankListTTL time.Duration `env:"ZALOPAY_BANK_LIST_TTL" envDefault:"1h"`
+	// Descriptions maps a locale to the description template, "{order_code}"
+	// being replaced by the order code: "vi:Thanh toán đơn hàng #{order_code};en:...".
+	Descriptions  map[string]string `env:"ZALOPAY_DESCRIPTIONS" envSeparator:";" envDefault:"vi:Thanh toán đơn hàng #{order_code};en:Payment for order #{order_code}"`
+	DefaultLocale string            `env:"ZALOPAY_DEFAULT_LOCALE" envDefault:"vi"`
 }
 
+// MockGatewayConfig holds the end-to-end testing gateway's credentials. The
+// gateway is never registered when APP_ENV is production.
+type MockGatewayConfig struct {
+	Secret string `env:"MOCK_GATEWAY_SECRET" envDefault:"mock-secret"`
+	// KeysJSON replaces Secret with versioned keys, a JSON array of
+	// {"version","secret","activates_at","expires_at"}.
+	KeysJSON string `env:"MOCK_GATEWAY_KEYS"`
+	Host     string `env:"MOCK_GATEWAY_HOST" envDefault:"http://localhost:8080"`
+}
+
+// TemporalConfig also configures cmd/worker: PaymentTaskQueue is the queue
+// it polls, and WorkerStopTimeout how long running activities get to finish
+// on shutdown. OrderTaskQueue and the workflow timeouts apply to the order
+// service's post-payment workflow, started when no pre-payment workflow is
+// left to signal. Payments on gateways with a status API are polled by the
+// worker from PollInitialInterval, doubling up to PollMaxInterval, until
+// they settle or PollTimeout passes, when they are failed as expired. It
+// must outlast the gateways' own order expiry; zero disables polling.
+// SearchAttributes tags started workflows with the payment's search
+// attributes; enable it only once they are registered in the namespace, as
+// starts fail otherwise.
 type TemporalConfig struct {
-	HostPort  string `env:"TEMPORAL_HOST_PORT" envDefault:"localhost:7233"`
-	Namespace string `env:"TEMPORAL_NAMESPACE" envDefault:"default"`
+	HostPort                 string        `env:"TEMPORAL_HOST_PORT" envDefault:"localhost:7233"`
+	Namespace                string        `env:"TEMPORAL_NAMESPACE" envDefault:"default"`
+	PaymentTaskQueue         string        `env:"TEMPORAL_PAYMENT_TASK_QUEUE" envDefault:"PAYMENT_TASK_QUEUE"`
+	WorkerStopTimeout        time.Duration `env:"TEMPORAL_WORKER_STOP_TIMEOUT" envDefault:"30s"`
+	OrderTaskQueue           string        `env:"TEMPORAL_ORDER_TASK_QUEUE" envDefault:"POST_PAYMENT_ORDER_TASK_QUEUE"`
+	WorkflowExecutionTimeout time.Duration `env:"TEMPORAL_WORKFLOW_EXECUTION_TIMEOUT" envDefault:"24h"`
+	WorkflowRunTimeout       time.Duration `env:"TEMPORAL_WORKFLOW_RUN_TIMEOUT" envDefault:"24h"`
+	WorkflowTaskTimeout      time.Duration `env:"TEMPORAL_WORKFLOW_TASK_TIMEOUT" envDefault:"1m"`
+	PollInitialInterval      time.Duration `env:"PAYMENT_POLL_INITIAL_INTERVAL" envDefault:"30s"`
+	PollMaxInterval          time.Duration `env:"PAYMENT_POLL_MAX_INTERVAL" envDefault:"5m"`
+	PollTimeout              time.Duration `env:"PAYMENT_POLL_TIMEOUT" envDefault:"30m"`
+	SearchAttributes         bool          `env:"TEMPORAL_SEARCH_ATTRIBUTES" envDefault:"false"`
 }
 
+// OutboxConfig: verified callbacks are queued in Mongo before their order
+// workflow is signalled, so they survive Temporal being down. The worker's
+// dispatcher looks for due entries every DispatchInterval, taking up to
+// BatchSize at a time; a failed entry is retried from RetryInitialInterval,
+// doubling up to RetryMaxInterval, and dead-lettered after MaxAttempts. An
+// attempt not finished within Lease, e.g. because its process died, is
+// picked up again.
+type OutboxConfig struct {
+	DispatchInterval     time.Duration `env:"CALLBACK_OUTBOX_DISPATCH_INTERVAL" envDefault:"5s"`
+	BatchSize            int           `env:"CALLBACK_OUTBOX_BATCH_SIZE" envDefault:"100"`
+	MaxAttempts          int           `env:"CALLBACK_OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	RetryInitialInterval time.Duration `env:"CALLBACK_OUTBOX_RETRY_INITIAL_INTERVAL" envDefault:"10s"`
+	RetryMaxInterval     time.Duration `env:"CALLBACK_OUTBOX_RETRY_MAX_INTERVAL" envDefault:"10m"`
+	Lease                time.Duration `env:"CALLBACK_OUTBOX_LEASE" envDefault:"1m"`
+}
+
+type MongoConfig struct {
+	URI      string `env:"MONGO_URI" envDefault:"mongodb://localhost:27018"`
+	Database string `env:"MONGO_DATABASE" envDefault:"payment"`
+}
+
+// StatementConfig describes the CSV export layout of the bank account used
+// for transfer reconciliation. Column indexes are zero-based, -1 means absent.
+type StatementConfig struct {
+	CSVDelimiter          string `env:"STATEMENT_CSV_DELIMITER" envDefault:","`
+	CSVSkipRows           int    `env:"STATEMENT_CSV_SKIP_ROWS" envDefault:"1"`
+	CSVDateColumn         int    `env:"STATEMENT_CSV_DATE_COLUMN" envDefault:"0"`
+	CSVDateLayout         string `env:"STATEMENT_CSV_DATE_LAYOUT" envDefault:"02/01/2006"`
+	CSVIDColumn           int    `env:"STATEMENT_CSV_ID_COLUMN" envDefault:"1"`
+	CSVAmountColumn       int    `env:"STATEMENT_CSV_AMOUNT_COLUMN" envDefault:"-1"`
+	CSVCreditColumn       int    `env:"STATEMENT_CSV_CREDIT_COLUMN" envDefault:"3"`
+	CSVDebitColumn        int    `env:"STATEMENT_CSV_DEBIT_COLUMN" envDefault:"2"`
+	CSVReferenceColumn    int    `env:"STATEMENT_CSV_REFERENCE_COLUMN" envDefault:"-1"`
+	CSVDescriptionColumn  int    `env:"STATEMENT_CSV_DESCRIPTION_COLUMN" envDefault:"4"`
+	CSVCounterpartyColumn int    `env:"STATEMENT_CSV_COUNTERPARTY_COLUMN" envDefault:"-1"`
+	CSVCurrency           string `env:"STATEMENT_CSV_CURRENCY" envDefault:"VND"`
+	CSVDecimalSeparator   string `env:"STATEMENT_CSV_DECIMAL_SEPARATOR" envDefault:","`
+	CSVThousandsSeparator string `env:"STATEMENT_CSV_THOUSANDS_SEPARATOR" envDefault:"."`
+}
+
+// RoutingConfig holds the rules used to pick a gateway when a payment request
+// does not name a provider. ROUTING_RULES is a JSON array of RoutingRule.
+type RoutingConfig struct {
+	RulesJSON string `env:"ROUTING_RULES" envDefault:"[{\"provider\":\"zalopay\",\"weight\":100}]"`
+	Rules     []RoutingRule
+}
+
+// RoutingRule makes a provider eligible for payments matching every set
+// condition. Zero MaxAmount and empty lists mean no restriction.
+type RoutingRule struct {
+	Provider   string   `json:"provider"`
+	MinAmount  float64  `json:"min_amount"`
+	MaxAmount  float64  `json:"max_amount"`
+	Currencies []string `json:"currencies"`
+	Segments   []string `json:"segments"`
+	Weight     int      `json:"weight"`
+}
+
+// CircuitBreakerConfig applies to every gateway. A breaker opens when at
+// least MinRequests calls are in the window and the failure rate reaches
+// FailureRate; calls slower than SlowCall count as failures.
+type CircuitBreakerConfig struct {
+	WindowSize     int           `env:"CIRCUIT_BREAKER_WINDOW_SIZE" envDefault:"20"`
+	MinRequests    int           `env:"CIRCUIT_BREAKER_MIN_REQUESTS" envDefault:"5"`
+	FailureRate    float64       `env:"CIRCUIT_BREAKER_FAILURE_RATE" envDefault:"0.5"`
+	SlowCall       time.Duration `env:"CIRCUIT_BREAKER_SLOW_CALL" envDefault:"5s"`
+	OpenTimeout    time.Duration `env:"CIRCUIT_BREAKER_OPEN_TIMEOUT" envDefault:"30s"`
+	HalfOpenProbes int           `env:"CIRCUIT_BREAKER_HALF_OPEN_PROBES" envDefault:"1"`
+}
+
+// GatewayHTTPConfig applies to every outbound call to a provider. Only
+// idempotent calls, such as status queries, are retried. RedactFields adds
+// to LOG_REDACT_FIELDS for request and response logs.
+type GatewayHTTPConfig struct {
+	Timeout      time.Duration `env:"GATEWAY_HTTP_TIMEOUT" envDefault:"15s"`
+	MaxRetries   int           `env:"GATEWAY_HTTP_MAX_RETRIES" envDefault:"2"`
+	RetryBackoff time.Duration `env:"GATEWAY_HTTP_RETRY_BACKOFF" envDefault:"200ms"`
+	RedactFields []string      `env:"GATEWAY_HTTP_REDACT_FIELDS" envDefault:"mac,key1,key2,zp_trans_token,order_token"`
+}
+
+// MetricsConfig is the admin port the gRPC server exposes /metrics and
+// /health on. The HTTP server serves both on its own port.
+type MetricsConfig struct {
+	Port string `env:"METRICS_PORT" envDefault:"9090"`
+}
+
+// GrpcMicroserviceConfig: the product service is only called by the worker,
+// to release inventory when compensating a failed payment.
 type GrpcMicroserviceConfig struct {
-	OrderSvcAddr string `env:"ORDER_SERVICE_ADDRESS" envDefault:"localhost:50054"`
+	OrderSvcAddr   string `env:"ORDER_SERVICE_ADDRESS" envDefault:"localhost:50054"`
+	ProductSvcAddr string `env:"PRODUCT_SERVICE_ADDRESS" envDefault:"localhost:50053"`
 }
 
 type HttpConfig struct {
 	Port string `env:"HTTP_PORT" envDefault:"8080"`
 }
 
+// ReturnConfig: customers redirected back by a provider land on the HTTP
+// server, which verifies the redirect and sends them on to the frontend's
+// SuccessURL, FailureURL or PendingURL with the bookingCode and status.
+// ConfirmStatus asks the provider's status API about payments no callback
+// has settled yet rather than trusting the redirect.
+type ReturnConfig struct {
+	SuccessURL    string `env:"RETURN_SUCCESS_URL" envDefault:"http://localhost:3000/payment/success"`
+	FailureURL    string `env:"RETURN_FAILURE_URL" envDefault:"http://localhost:3000/payment/failure"`
+	PendingURL    string `env:"RETURN_PENDING_URL" envDefault:"http://localhost:3000/payment/pending"`
+	ConfirmStatus bool   `env:"RETURN_CONFIRM_STATUS" envDefault:"true"`
+}
+
 func Load() (*Config, error) {
 	godotenv.Load()
 	cfg := &Config{}
@@ -54,6 +254,14 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if err := json.Unmarshal([]byte(cfg.PayGateway.DefinitionsJSON), &cfg.PayGateway.Definitions); err != nil {
+		return nil, fmt.Errorf("invalid GATEWAYS: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(cfg.Routing.RulesJSON), &cfg.Routing.Rules); err != nil {
+		return nil, fmt.Errorf("invalid ROUTING_RULES: %w", err)
+	}
+
 	// Process the LOG_REDACT_FIELDS env var
 	if len(cfg.Log.RedactFields) == 1 && strings.Contains(cfg.Log.RedactFields[0], ",") {
 		cfg.Log.RedactFields = strings.Split(cfg.Log.RedactFields[0], ",")
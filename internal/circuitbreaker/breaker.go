@@ -0,0 +1,126 @@
+package circuitbreaker
+
+import "time"
+
+// Execute runs fn unless the breaker is open, in which case it returns ErrOpen
+// without calling fn. Once OpenTimeout has passed a limited number of probe
+// calls go through; their outcome closes or re-opens the breaker.
+func (b *Breaker) Execute(fn func() error) error {
+	if err := b.before(); err != nil {
+		return err
+	}
+
+	start := b.now()
+	err := fn()
+	failed := b.settings.IsFailure(err) ||
+		(b.settings.SlowCallThreshold > 0 && b.now().Sub(start) > b.settings.SlowCallThreshold)
+
+	b.after(failed)
+	return err
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	return b.state
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	return Snapshot{
+		State:       b.state,
+		Requests:    b.filled,
+		FailureRate: b.failureRate(),
+		OpenedAt:    b.openedAt,
+	}
+}
+
+func (b *Breaker) before() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.settings.HalfOpenMaxCalls {
+			return ErrOpen
+		}
+		b.probes++
+	}
+	return nil
+}
+
+func (b *Breaker) after(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		if failed {
+			b.setState(StateOpen)
+		} else {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.window[b.next] = failed
+	b.next = (b.next + 1) % len(b.window)
+	if b.filled < len(b.window) {
+		b.filled++
+	}
+
+	if b.state == StateClosed && b.filled >= b.settings.MinRequests &&
+		b.failureRate() >= b.settings.FailureRateThreshold {
+		b.setState(StateOpen)
+	}
+}
+
+// refresh moves an open breaker to half-open once its timeout has passed.
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) setState(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+
+	b.state = to
+	b.probes = 0
+	switch to {
+	case StateOpen:
+		b.openedAt = b.now()
+	case StateClosed:
+		b.window = make([]bool, len(b.window))
+		b.next, b.filled = 0, 0
+		b.openedAt = time.Time{}
+	}
+
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
+
+func (b *Breaker) failureRate() float64 {
+	if b.filled == 0 {
+		return 0
+	}
+
+	failures := 0
+	for i := 0; i < b.filled; i++ {
+		if b.window[i] {
+			failures++
+		}
+	}
+	return float64(failures) / float64(b.filled)
+}
@@ -0,0 +1,7 @@
+package circuitbreaker
+
+import "errors"
+
+var (
+	ErrOpen = errors.New("circuit breaker is open")
+)
@@ -0,0 +1,40 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+type Breaker struct {
+	settings Settings
+
+	mu       sync.Mutex
+	state    State
+	window   []bool
+	next     int
+	filled   int
+	openedAt time.Time
+	probes   int
+	now      func() time.Time
+}
+
+func New(s Settings) *Breaker {
+	if s.WindowSize <= 0 {
+		s.WindowSize = 20
+	}
+	if s.MinRequests <= 0 {
+		s.MinRequests = 1
+	}
+	if s.HalfOpenMaxCalls <= 0 {
+		s.HalfOpenMaxCalls = 1
+	}
+	if s.IsFailure == nil {
+		s.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	return &Breaker{
+		settings: s,
+		window:   make([]bool, s.WindowSize),
+		now:      time.Now,
+	}
+}
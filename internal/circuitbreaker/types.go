@@ -0,0 +1,44 @@
+package circuitbreaker
+
+import "time"
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Settings configure when a breaker opens. A call counts as failed when
+// IsFailure reports true for its error or it takes longer than SlowCallThreshold.
+type Settings struct {
+	WindowSize           int
+	MinRequests          int
+	FailureRateThreshold float64
+	SlowCallThreshold    time.Duration
+	OpenTimeout          time.Duration
+	HalfOpenMaxCalls     int
+	IsFailure            func(err error) bool
+	OnStateChange        func(from, to State)
+}
+
+type Snapshot struct {
+	State       State
+	Requests    int
+	FailureRate float64
+	OpenedAt    time.Time
+}
@@ -0,0 +1,169 @@
+package gatewayhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/metrics"
+)
+
+// PostJSON sends in as JSON and decodes the response into out.
+func (c *Client) PostJSON(ctx context.Context, endpoint string, rawURL string, in interface{}, out interface{}, opts CallOptions) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	res, err := c.Do(ctx, Request{
+		Endpoint:    endpoint,
+		Method:      http.MethodPost,
+		URL:         rawURL,
+		ContentType: "application/json",
+		Body:        body,
+		Idempotent:  opts.Idempotent,
+		Timeout:     opts.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	return decodeJSON(res.Body, out)
+}
+
+// PostForm sends form URL-encoded and decodes the JSON response into out.
+func (c *Client) PostForm(ctx context.Context, endpoint string, rawURL string, form url.Values, out interface{}, opts CallOptions) error {
+	res, err := c.Do(ctx, Request{
+		Endpoint:    endpoint,
+		Method:      http.MethodPost,
+		URL:         rawURL,
+		ContentType: "application/x-www-form-urlencoded",
+		Body:        []byte(form.Encode()),
+		Idempotent:  opts.Idempotent,
+		Timeout:     opts.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	return decodeJSON(res.Body, out)
+}
+
+func decodeJSON(body []byte, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Do sends the request, retrying idempotent ones on network errors, 5xx
+// and 429 with exponential backoff. Non-2xx responses are returned as a
+// *StatusError alongside the response.
+func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	var (
+		res *Response
+		err error
+	)
+	for attempt := 0; ; attempt++ {
+		res, err = c.do(ctx, req, attempt)
+		if err == nil || !req.Idempotent || attempt >= c.settings.MaxRetries || !retryable(err) {
+			return res, err
+		}
+
+		wait := c.backoff(attempt)
+		select {
+		case <-ctx.Done():
+			return res, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, req Request, attempt int) (*Response, error) {
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = c.settings.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if req.ContentType != "" {
+		httpReq.Header.Set("Content-Type", req.ContentType)
+	}
+
+	c.logf(ctx, "gateway request: gateway=%s endpoint=%s method=%s url=%s attempt=%d body=%s",
+		c.gateway, req.Endpoint, req.Method, req.URL, attempt+1, c.redactBody(req.ContentType, req.Body))
+
+	start := time.Now()
+	resp, err := c.http.Do(httpReq)
+	elapsed := time.Since(start)
+	metrics.GatewayHTTPDuration.WithLabelValues(c.gateway, req.Endpoint).Observe(elapsed.Seconds())
+	if err != nil {
+		metrics.GatewayHTTPRequests.WithLabelValues(c.gateway, req.Endpoint, "error").Inc()
+		c.warnf(ctx, "gateway request failed: gateway=%s endpoint=%s attempt=%d duration=%s error=%v",
+			c.gateway, req.Endpoint, attempt+1, elapsed, err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	metrics.GatewayHTTPRequests.WithLabelValues(c.gateway, req.Endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	c.logf(ctx, "gateway response: gateway=%s endpoint=%s status=%d duration=%s body=%s",
+		c.gateway, req.Endpoint, resp.StatusCode, elapsed, c.redactBody(resp.Header.Get("Content-Type"), body))
+
+	res := &Response{StatusCode: resp.StatusCode, Body: body}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return res, &StatusError{StatusCode: resp.StatusCode, Body: body}
+	}
+	return res, nil
+}
+
+// backoff doubles RetryBackoff per attempt and adds up to 50% jitter so
+// retries from many requests do not arrive together.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.settings.RetryBackoff << attempt
+	return d + time.Duration(rand.Int64N(int64(d)/2+1))
+}
+
+func retryable(err error) bool {
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+func (c *Client) logf(ctx context.Context, template string, args ...any) {
+	if c.l != nil {
+		c.l.Infof(ctx, template, args...)
+	}
+}
+
+func (c *Client) warnf(ctx context.Context, template string, args ...any) {
+	if c.l != nil {
+		c.l.Warnf(ctx, template, args...)
+	}
+}
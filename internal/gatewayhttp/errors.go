@@ -0,0 +1,29 @@
+package gatewayhttp
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnavailable wraps 5xx and 429 responses: the provider is up but
+	// cannot serve the call right now.
+	ErrUnavailable = errors.New("gateway returned a server error")
+)
+
+// StatusError is returned for any non-2xx response.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("gateway returned status %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	if retryableStatus(e.StatusCode) {
+		return ErrUnavailable
+	}
+	return nil
+}
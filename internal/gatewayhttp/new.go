@@ -0,0 +1,53 @@
+package gatewayhttp
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/pkg/log"
+)
+
+const (
+	defaultTimeout      = 15 * time.Second
+	defaultRetryBackoff = 200 * time.Millisecond
+)
+
+// Client is the HTTP client every gateway talks to its provider through. It
+// is safe for concurrent use.
+type Client struct {
+	gateway  string
+	l        log.Logger
+	settings Settings
+	redact   map[string]bool
+	http     *http.Client
+}
+
+// New creates the client of one gateway instance. gateway labels logs and
+// metrics; l may be nil to disable logging.
+func New(gateway string, l log.Logger, s Settings) *Client {
+	if s.Timeout <= 0 {
+		s.Timeout = defaultTimeout
+	}
+	if s.MaxRetries < 0 {
+		s.MaxRetries = 0
+	}
+	if s.RetryBackoff <= 0 {
+		s.RetryBackoff = defaultRetryBackoff
+	}
+
+	redact := make(map[string]bool, len(s.RedactFields))
+	for _, f := range s.RedactFields {
+		if f = strings.TrimSpace(f); f != "" {
+			redact[strings.ToLower(f)] = true
+		}
+	}
+
+	return &Client{
+		gateway:  gateway,
+		l:        l,
+		settings: s,
+		redact:   redact,
+		http:     &http.Client{Transport: s.Transport},
+	}
+}
@@ -0,0 +1,58 @@
+package gatewayhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// redactBody renders a body for the logs with the configured fields masked.
+// Bodies that are neither JSON nor a form are summarised by size only.
+func (c *Client) redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return "-"
+	}
+
+	if strings.Contains(contentType, "x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err == nil {
+			for k := range form {
+				if c.redact[strings.ToLower(k)] {
+					form.Set(k, redacted)
+				}
+			}
+			return form.Encode()
+		}
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		b, err := json.Marshal(c.redactValue(v))
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprintf("<%d bytes>", len(body))
+}
+
+func (c *Client) redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range t {
+			if c.redact[strings.ToLower(k)] {
+				t[k] = redacted
+				continue
+			}
+			t[k] = c.redactValue(fv)
+		}
+	case []interface{}:
+		for i, iv := range t {
+			t[i] = c.redactValue(iv)
+		}
+	}
+	return v
+}
@@ -0,0 +1,139 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func (c *Cassette) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req := RecordedRequest{
+		Method:      r.Method,
+		URL:         c.scrubber.scrubText(r.URL.Path),
+		ContentType: r.Header.Get("Content-Type"),
+		Body:        c.scrubber.scrubBody(r.Header.Get("Content-Type"), string(body)),
+	}
+
+	if c.mode == ModeRecord {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		return c.record(r, req)
+	}
+	return c.replay(r, req)
+}
+
+func (c *Cassette) record(r *http.Request, req RecordedRequest) (*http.Response, error) {
+	resp, err := c.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: req,
+		Response: RecordedResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: contentType,
+			Body:        c.scrubber.scrubBody(contentType, string(body)),
+		},
+	})
+	c.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// replay answers with the first unused interaction matching the method,
+// path and scrubbed body; each one is played once.
+func (c *Cassette) replay(r *http.Request, req RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var closest *RecordedRequest
+	for i, in := range c.interactions {
+		if c.used[i] || in.Request.Method != req.Method || in.Request.URL != req.URL {
+			continue
+		}
+		if in.Request.Body != req.Body {
+			closest = &c.interactions[i].Request
+			continue
+		}
+
+		c.used[i] = true
+		header := http.Header{}
+		if in.Response.ContentType != "" {
+			header.Set("Content-Type", in.Response.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewBufferString(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       r,
+		}, nil
+	}
+
+	if closest != nil {
+		return nil, fmt.Errorf("%w: %s %s body differs\nrecorded: %s\n     got: %s",
+			ErrNoInteraction, req.Method, req.URL, closest.Body, req.Body)
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+}
+
+// Save writes the recorded interactions. It does nothing when replaying.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(fixture{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// Unused lists the replayed fixture's interactions no request matched, so a
+// test can tell when the gateway stopped making a call.
+func (c *Cassette) Unused() []RecordedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unused []RecordedRequest
+	for i, in := range c.interactions {
+		if c.mode == ModeReplay && !c.used[i] {
+			unused = append(unused, in.Request)
+		}
+	}
+	return unused
+}
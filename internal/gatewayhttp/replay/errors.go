@@ -0,0 +1,5 @@
+package replay
+
+import "errors"
+
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
@@ -0,0 +1,71 @@
+// Package replay records gateway HTTP traffic into fixture files and plays
+// it back in tests, so provider contracts can be checked without network
+// access. Plug a Cassette in through gatewayhttp.Settings.Transport.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// RecordEnv set to "record" makes ModeFromEnv record against the real
+// provider instead of replaying.
+const RecordEnv = "GATEWAY_FIXTURES"
+
+type Mode int
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+)
+
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) == "record" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Cassette is an http.RoundTripper backed by one fixture file. It is safe
+// for concurrent use.
+type Cassette struct {
+	path     string
+	mode     Mode
+	scrubber Scrubber
+	next     http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New opens the fixture at path. In ModeReplay the file must exist; in
+// ModeRecord requests go to the real provider and Save writes the file.
+func New(path string, mode Mode, s Scrubber) (*Cassette, error) {
+	c := &Cassette{
+		path:     path,
+		mode:     mode,
+		scrubber: s,
+		next:     http.DefaultTransport,
+	}
+	if mode == ModeRecord {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	c.interactions = f.Interactions
+	c.used = make([]bool, len(f.Interactions))
+
+	return c, nil
+}
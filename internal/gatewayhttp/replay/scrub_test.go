@@ -0,0 +1,17 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubBodyKeepsFieldOrder(t *testing.T) {
+	s := Scrubber{Fields: []string{"token", "mac"}}
+
+	form := s.scrubBody("application/x-www-form-urlencoded", "reqtime=1&token=abc&appid=2553&mac=d%2Bf")
+	require.Equal(t, "reqtime=1&token=%5BSCRUBBED%5D&appid=2553&mac=%5BSCRUBBED%5D", form)
+
+	json := s.scrubBody("application/json", `{"token":"abc","amount":1,"mac":"def"}`)
+	require.Equal(t, `{"token":"[SCRUBBED]","amount":1,"mac":"[SCRUBBED]"}`, json)
+}
@@ -0,0 +1,41 @@
+package replay
+
+import "regexp"
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one scrubbed request and the response it got.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Scrubber removes what changes between runs or must not be committed.
+// Fields are JSON or form field names: string values become "[SCRUBBED]"
+// and numbers 0. Secrets are literal values replaced wherever they appear,
+// and Replacements run last over URLs and bodies.
+type Scrubber struct {
+	Fields       []string
+	Secrets      []string
+	Replacements []Replacement
+}
+
+type Replacement struct {
+	Pattern *regexp.Regexp
+	With    string
+}
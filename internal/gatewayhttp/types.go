@@ -0,0 +1,44 @@
+package gatewayhttp
+
+import (
+	"net/http"
+	"time"
+)
+
+// Settings apply to every call of a client. Request.Timeout overrides
+// Timeout for a single endpoint.
+type Settings struct {
+	Timeout      time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// RedactFields are JSON or form field names whose values never reach
+	// the logs, matched case-insensitively.
+	RedactFields []string
+	// Transport replaces the default transport, e.g. with a replay
+	// cassette in tests.
+	Transport http.RoundTripper
+}
+
+// Request is one call to a provider. Endpoint is a short name such as
+// "query", used in logs and metrics instead of the full URL. Only
+// Idempotent requests are retried.
+type Request struct {
+	Endpoint    string
+	Method      string
+	URL         string
+	ContentType string
+	Body        []byte
+	Idempotent  bool
+	Timeout     time.Duration
+}
+
+// CallOptions are the per-call parts of a Request for PostJSON and PostForm.
+type CallOptions struct {
+	Idempotent bool
+	Timeout    time.Duration
+}
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
@@ -1,29 +1,33 @@
 package httpserver
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/gorilla/mux"
 	"github.com/vogiaan1904/payment-svc/internal/models"
 	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
-	zpGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
 )
 
-func (s *Server) handleZalopayCallback(w http.ResponseWriter, r *http.Request) {
-	var callbackData zpGW.ZalopayCallbackData
-	if err := json.NewDecoder(r.Body).Decode(&callbackData); err != nil {
-		s.logger.Errorf(r.Context(), "Failed to decode callback data: %v", err)
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
+// handleProviderCallback hands the raw request to the provider's gateway,
+// which verifies it and writes the acknowledgement itself.
+func (s *Server) handleProviderCallback(w http.ResponseWriter, r *http.Request) {
+	s.handleCallback(w, r, models.GatewayType(mux.Vars(r)["provider"]))
+}
 
-	if err := bankTf.HandlePaymentCallback(s.paymentSvc, r.Context(), callbackData, models.GatewayTypeZalopay); err != nil {
-		s.logger.Errorf(r.Context(), "Failed to process callback: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+// handleLegacyZalopayCallback serves orders created while ZaloPay was told
+// to call back on /zalopay/callback.
+func (s *Server) handleLegacyZalopayCallback(w http.ResponseWriter, r *http.Request) {
+	s.handleCallback(w, r, models.GatewayTypeZalopay)
+}
+
+func (s *Server) handleCallback(w http.ResponseWriter, r *http.Request, gatewayType models.GatewayType) {
+	err := bankTf.HandlePaymentCallback(s.paymentSvc, r.Context(), gatewayType, w, r)
+	if errors.Is(err, bankTf.ErrInvalidGateway) {
+		http.NotFound(w, r)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	if err != nil {
+		s.logger.Errorf(r.Context(), "Failed to process %s callback: %v", gatewayType, err)
+	}
 }
@@ -0,0 +1,63 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/circuitbreaker"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+)
+
+type gatewayHealthResponse struct {
+	Environment string     `json:"environment,omitempty"`
+	State       string     `json:"state"`
+	Requests    int        `json:"requests"`
+	FailureRate float64    `json:"failure_rate"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+}
+
+type healthResponse struct {
+	Status   string                           `json:"status"`
+	Gateways map[string]gatewayHealthResponse `json:"gateways"`
+}
+
+// HealthHandler reports the process as up, and as degraded while any gateway
+// breaker is open. It always answers 200 so a tripped provider does not get
+// the whole service restarted. Gateways not called yet show as closed.
+func HealthHandler(health *bankTf.GatewayHealth, gwf *bankTf.GatewayFactory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := healthResponse{
+			Status:   "ok",
+			Gateways: make(map[string]gatewayHealthResponse),
+		}
+
+		envs := gwf.Environments()
+		for gt, env := range envs {
+			res.Gateways[string(gt)] = gatewayHealthResponse{
+				Environment: env,
+				State:       circuitbreaker.StateClosed.String(),
+			}
+		}
+
+		for gt, snap := range health.Snapshot() {
+			gh := gatewayHealthResponse{
+				Environment: envs[gt],
+				State:       snap.State.String(),
+				Requests:    snap.Requests,
+				FailureRate: snap.FailureRate,
+			}
+			if !snap.OpenedAt.IsZero() {
+				gh.OpenedAt = &snap.OpenedAt
+			}
+			if snap.State == circuitbreaker.StateOpen {
+				res.Status = "degraded"
+			}
+			res.Gateways[string(gt)] = gh
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(res)
+	}
+}
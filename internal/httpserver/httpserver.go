@@ -3,9 +3,16 @@ package httpserver
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	mockGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/mock"
 	"github.com/vogiaan1904/payment-svc/pkg/log"
 	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
 )
@@ -14,9 +21,16 @@ type Server struct {
 	server     *http.Server
 	logger     log.Logger
 	paymentSvc payment.PaymentServiceServer
+	health     *bankTf.GatewayHealth
+	gwf        *bankTf.GatewayFactory
+	mockGW     *mockGW.MockGateway
+	returnCfg  config.ReturnConfig
 }
 
-func New(addr string, logger log.Logger, paymentSvc payment.PaymentServiceServer) *Server {
+// New creates the HTTP server. mockGateway is nil unless the mock gateway is
+// enabled, in which case its payment page and control API are mounted.
+// returnCfg says where customers landing on /returns/{provider} go next.
+func New(addr string, logger log.Logger, paymentSvc payment.PaymentServiceServer, health *bankTf.GatewayHealth, gwf *bankTf.GatewayFactory, mockGateway *mockGW.MockGateway, returnCfg config.ReturnConfig) *Server {
 	router := mux.NewRouter()
 
 	server := &Server{
@@ -26,6 +40,10 @@ func New(addr string, logger log.Logger, paymentSvc payment.PaymentServiceServer
 		},
 		logger:     logger,
 		paymentSvc: paymentSvc,
+		health:     health,
+		gwf:        gwf,
+		mockGW:     mockGateway,
+		returnCfg:  returnCfg,
 	}
 
 	server.registerRoutes(router)
@@ -47,9 +65,44 @@ func (s *Server) Shutdown(ctx context.Context) error {
 }
 
 func (s *Server) registerRoutes(router *mux.Router) {
-	router.HandleFunc("/zalopay/callback", s.handleZalopayCallback).Methods(http.MethodPost)
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}).Methods(http.MethodGet)
+	// Paths a gateway instance was configured with go first, so they win
+	// over the generic routes below.
+	s.registerGatewayRoutes(router)
+	router.HandleFunc("/callbacks/{provider}", s.handleProviderCallback).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/zalopay/callback", s.handleLegacyZalopayCallback).Methods(http.MethodPost)
+	router.HandleFunc("/returns/{provider}", s.handleProviderReturn).Methods(http.MethodGet)
+	if s.mockGW != nil {
+		router.HandleFunc(mockGW.PaymentPagePath+"{orderCode}", s.handleMockPaymentPage).Methods(http.MethodGet)
+		router.HandleFunc("/mock/orders/{orderCode}/scenario", s.handleSetMockScenario).Methods(http.MethodPut)
+	}
+	router.HandleFunc("/health", HealthHandler(s.health, s.gwf)).Methods(http.MethodGet)
+	router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+}
+
+// registerGatewayRoutes serves the callback and return paths every gateway
+// gave its provider. The defaults, /callbacks/{provider} and
+// /returns/{provider}, need no routes of their own.
+func (s *Server) registerGatewayRoutes(router *mux.Router) {
+	routes := s.gwf.Routes()
+	types := slices.Sorted(maps.Keys(routes))
+
+	owners := make(map[string]models.GatewayType)
+	serve := func(gt models.GatewayType, path string, defaultPrefix string, h func(http.ResponseWriter, *http.Request, models.GatewayType), methods ...string) {
+		if path == "" || path == defaultPrefix+string(gt) {
+			return
+		}
+		if owner, ok := owners[path]; ok {
+			s.logger.Warnf(context.Background(), "Path %s of %s is already served for %s", path, gt, owner)
+			return
+		}
+		owners[path] = gt
+		router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			h(w, r, gt)
+		}).Methods(methods...)
+	}
+
+	for _, gt := range types {
+		serve(gt, routes[gt].Callback, "/callbacks/", s.handleCallback, http.MethodGet, http.MethodPost)
+		serve(gt, routes[gt].Return, "/returns/", s.handleReturn, http.MethodGet)
+	}
 }
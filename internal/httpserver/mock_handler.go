@@ -0,0 +1,82 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	mockGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/mock"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockScenarioRequest struct {
+	Outcome      mockGW.Outcome `json:"outcome"`
+	DelayMs      int64          `json:"delay_ms"`
+	BadSignature bool           `json:"bad_signature"`
+}
+
+// handleMockPaymentPage stands in for the provider's hosted page: opening it
+// plays the order's scenario and tells the tester what will happen. Like a
+// provider, it charges the amount of the stored payment, not one from the
+// link.
+func (s *Server) handleMockPaymentPage(w http.ResponseWriter, r *http.Request) {
+	oCode := mux.Vars(r)["orderCode"]
+	res, err := s.paymentSvc.GetPaymentStatus(r.Context(), &payment.GetPaymentStatusRequest{
+		PaymentIdentifier: &payment.GetPaymentStatusRequest_OrderCode{OrderCode: oCode},
+	})
+	if err != nil {
+		s.logger.Warnf(r.Context(), "Mock payment page for order %s: %v", oCode, err)
+		if status.Code(err) == codes.NotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	if res.Payment.GetProvider() != string(models.GatewayTypeMock) {
+		http.NotFound(w, r)
+		return
+	}
+	amount := res.Payment.GetAmount()
+
+	sc := s.mockGW.Scenario(oCode, mockGW.Outcome(r.URL.Query().Get("outcome")))
+	if err := s.mockGW.Simulate(oCode, amount, sc); err != nil {
+		s.logger.Errorf(r.Context(), "Failed to simulate mock payment: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<html><body><h1>Mock payment</h1><p>Order: %s</p><p>Amount: %s</p><p>Outcome: %s, delay: %s, bad signature: %t</p></body></html>",
+		html.EscapeString(oCode), strconv.FormatFloat(amount, 'f', -1, 64), html.EscapeString(string(sc.Outcome)), sc.Delay, sc.BadSignature)
+}
+
+func (s *Server) handleSetMockScenario(w http.ResponseWriter, r *http.Request) {
+	var req mockScenarioRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Outcome {
+	case mockGW.OutcomeSucceed, mockGW.OutcomeFail, mockGW.OutcomeNone:
+	default:
+		http.Error(w, "outcome must be succeed, fail or none", http.StatusBadRequest)
+		return
+	}
+
+	s.mockGW.SetScenario(mux.Vars(r)["orderCode"], mockGW.Scenario{
+		Outcome:      req.Outcome,
+		Delay:        time.Duration(req.DelayMs) * time.Millisecond,
+		BadSignature: req.BadSignature,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
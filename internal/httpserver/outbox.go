@@ -0,0 +1,104 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+)
+
+const defaultOutboxListLimit = 100
+
+type outboxEntryResponse struct {
+	ID            string     `json:"id"`
+	OrderCode     string     `json:"order_code"`
+	PaymentID     string     `json:"payment_id,omitempty"`
+	Provider      string     `json:"provider"`
+	PaymentStatus string     `json:"payment_status"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DispatchedAt  *time.Time `json:"dispatched_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// OutboxListHandler lists callback outbox entries, dead letters by default:
+// GET ?status=pending|dispatched|dead_letter&limit=N.
+func OutboxListHandler(d *bankTf.CallbackDispatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		st := models.OutboxStatus(r.URL.Query().Get("status"))
+		if st == "" {
+			st = models.OutboxStatusDeadLetter
+		}
+
+		limit := int64(defaultOutboxListLimit)
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n <= 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: bankTf.ErrInvalidInput.Error()})
+				return
+			}
+			limit = n
+		}
+
+		es, err := d.List(r.Context(), st, limit)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+
+		res := make([]outboxEntryResponse, 0, len(es))
+		for _, e := range es {
+			res = append(res, newOutboxEntryResponse(e))
+		}
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+// OutboxRedriveHandler re-drives the dead-lettered entry {id}.
+func OutboxRedriveHandler(d *bankTf.CallbackDispatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e, err := d.Redrive(r.Context(), r.PathValue("id"))
+		switch {
+		case errors.Is(err, bankTf.ErrOutboxEntryNotFound):
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		case errors.Is(err, bankTf.ErrOutboxEntryNotDead):
+			writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, newOutboxEntryResponse(e))
+		}
+	}
+}
+
+func newOutboxEntryResponse(e models.CallbackOutboxEntry) outboxEntryResponse {
+	return outboxEntryResponse{
+		ID:            e.ID.Hex(),
+		OrderCode:     e.OrderCode,
+		PaymentID:     e.PaymentID,
+		Provider:      e.Provider,
+		PaymentStatus: string(e.PaymentStatus),
+		Status:        string(e.Status),
+		Attempts:      e.Attempts,
+		LastError:     e.LastError,
+		NextAttemptAt: e.NextAttemptAt,
+		DispatchedAt:  e.DispatchedAt,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+)
+
+func (s *Server) handleProviderReturn(w http.ResponseWriter, r *http.Request) {
+	s.handleReturn(w, r, models.GatewayType(mux.Vars(r)["provider"]))
+}
+
+// handleReturn is the return URL providers send customers back to. The
+// redirect is verified before the customer is sent on to the frontend,
+// which only ever sees the bookingCode and a status it can trust. Forged
+// redirects get a 400; when the status cannot be worked out the customer
+// lands on the pending page, which polls for the outcome.
+func (s *Server) handleReturn(w http.ResponseWriter, r *http.Request, gatewayType models.GatewayType) {
+	res, err := bankTf.ResolvePaymentReturn(r.Context(), s.paymentSvc, gatewayType, r, s.returnCfg.ConfirmStatus)
+	switch {
+	case errors.Is(err, bankTf.ErrInvalidGateway), errors.Is(err, bankTf.ErrRedirectNotSupported):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, bankTf.ErrInvalidCallback), errors.Is(err, bankTf.ErrAmountMismatch):
+		http.Error(w, "invalid redirect", http.StatusBadRequest)
+		return
+	case err != nil:
+		s.logger.Errorf(r.Context(), "Failed to resolve %s return: %v", gatewayType, err)
+		res = bankTf.ReturnResult{OrderCode: r.URL.Query().Get("bookingCode"), Status: models.PaymentStatusPending}
+	}
+
+	target, err := s.returnTarget(res)
+	if err != nil {
+		s.logger.Errorf(r.Context(), "Invalid return URL for %s: %v", res.Status, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// returnTarget picks the frontend page for the payment's status and adds
+// the bookingCode and status to its query.
+func (s *Server) returnTarget(res bankTf.ReturnResult) (string, error) {
+	var base string
+	switch res.Status {
+	case models.PaymentStatusCompleted:
+		base = s.returnCfg.SuccessURL
+	case models.PaymentStatusPending:
+		base = s.returnCfg.PendingURL
+	default:
+		base = s.returnCfg.FailureURL
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if res.OrderCode != "" {
+		q.Set("bookingCode", res.OrderCode)
+	}
+	q.Set("status", string(res.Status))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,10 @@
+package keyring
+
+import "errors"
+
+var (
+	ErrNoKeys       = errors.New("keyring has no keys")
+	ErrInvalidKey   = errors.New("key version and secret are required")
+	ErrDuplicateKey = errors.New("key version defined more than once")
+	ErrNoActiveKey  = errors.New("no active key")
+)
@@ -0,0 +1,61 @@
+package keyring
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Current returns the active key that activated last, which is the one
+// outbound requests are signed with.
+func (r *Keyring) Current() (Key, error) {
+	now := r.now()
+
+	var (
+		cur   Key
+		found bool
+	)
+	for _, k := range r.keys {
+		if !k.activeAt(now) {
+			continue
+		}
+		if !found || k.ActivatesAt.After(cur.ActivatesAt) {
+			cur, found = k, true
+		}
+	}
+
+	if !found {
+		return Key{}, ErrNoActiveKey
+	}
+	return cur, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of data under the current key.
+func (r *Keyring) Sign(data string) (string, Key, error) {
+	k, err := r.Current()
+	if err != nil {
+		return "", Key{}, err
+	}
+	return HMAC(k.Secret, data), k, nil
+}
+
+// Verify checks mac against every active key and returns the version that
+// produced it.
+func (r *Keyring) Verify(data string, mac string) (string, bool) {
+	now := r.now()
+	for _, k := range r.keys {
+		if !k.activeAt(now) {
+			continue
+		}
+		if hmac.Equal([]byte(HMAC(k.Secret, data)), []byte(mac)) {
+			return k.Version, true
+		}
+	}
+	return "", false
+}
+
+func HMAC(secret string, data string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package keyring
+
+import (
+	"fmt"
+	"time"
+)
+
+// Keyring holds the versions of one HMAC-SHA256 secret so it can be rotated
+// without downtime: the new version is added with a future ActivatesAt and
+// the old one given an ExpiresAt past the overlap.
+type Keyring struct {
+	keys []Key
+	now  func() time.Time
+}
+
+func New(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.Version == "" || k.Secret == "" {
+			return nil, ErrInvalidKey
+		}
+		if seen[k.Version] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKey, k.Version)
+		}
+		seen[k.Version] = true
+	}
+
+	return &Keyring{
+		keys: keys,
+		now:  time.Now,
+	}, nil
+}
+
+// Single wraps a secret that is never rotated.
+func Single(version string, secret string) (*Keyring, error) {
+	return New(Key{Version: version, Secret: secret})
+}
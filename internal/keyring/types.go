@@ -0,0 +1,19 @@
+package keyring
+
+import "time"
+
+// Key is one version of a shared secret. A zero ActivatesAt means active
+// since forever, a zero ExpiresAt means it never expires.
+type Key struct {
+	Version     string    `json:"version"`
+	Secret      string    `json:"secret"`
+	ActivatesAt time.Time `json:"activates_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (k Key) activeAt(t time.Time) bool {
+	if !k.ActivatesAt.IsZero() && t.Before(k.ActivatesAt) {
+		return false
+	}
+	return k.ExpiresAt.IsZero() || t.Before(k.ExpiresAt)
+}
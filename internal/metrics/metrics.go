@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "payment"
+
+var (
+	// GatewayCircuitState is 0 when closed, 1 when half-open and 2 when open.
+	GatewayCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "gateway_circuit_state",
+		Help:      "Circuit breaker state per gateway (0 closed, 1 half-open, 2 open).",
+	}, []string{"gateway"})
+
+	GatewayHealthScore = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "gateway_health_score",
+		Help:      "Share of successful gateway calls in the breaker window, from 0 to 1.",
+	}, []string{"gateway"})
+
+	GatewayCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_calls_total",
+		Help:      "Gateway calls by outcome: success, failure or rejected by an open breaker.",
+	}, []string{"gateway", "result"})
+
+	GatewayCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gateway_call_duration_seconds",
+		Help:      "Latency of gateway calls that went through the breaker.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"gateway"})
+
+	GatewayHTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_http_requests_total",
+		Help:      "Outbound provider HTTP requests by endpoint and status code, or error when no response arrived.",
+	}, []string{"gateway", "endpoint", "status"})
+
+	GatewayHTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gateway_http_request_duration_seconds",
+		Help:      "Latency of single outbound provider HTTP requests, retries counted separately.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"gateway", "endpoint"})
+
+	// CallbackVerifications shows which key version verifies callbacks, so an
+	// old key can be retired once nothing is verified with it anymore.
+	CallbackVerifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_callback_verifications_total",
+		Help:      "Callback signature checks by key version and result: valid or invalid.",
+	}, []string{"gateway", "key_version", "result"})
+
+	Compensations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compensations_total",
+		Help:      "Compensations of failed payments by outcome: completed, skipped or incomplete.",
+	}, []string{"result"})
+
+	// CompensationStepFailures counts steps left undone after all retries;
+	// each needs a manual fix.
+	CompensationStepFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compensation_step_failures_total",
+		Help:      "Compensation steps that still failed after their retries.",
+	}, []string{"step"})
+
+	// CallbackDispatches counts attempts to signal queued callbacks; dead
+	// letters wait for an operator to re-drive them.
+	CallbackDispatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "callback_dispatches_total",
+		Help:      "Callback outbox dispatch attempts by result: dispatched, retry or dead_letter.",
+	}, []string{"result"})
+)
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending    OutboxStatus = "pending"
+	OutboxStatusDispatched OutboxStatus = "dispatched"
+	OutboxStatusDeadLetter OutboxStatus = "dead_letter"
+)
+
+// CallbackOutboxEntry is a verified callback whose payment was updated but
+// whose order workflow may not have been told yet. Key is unique per payment
+// and status, so a callback the gateway retries is queued once. A pending
+// entry is due at NextAttemptAt; claiming it moves that past the lease.
+type CallbackOutboxEntry struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	Key              string             `bson:"key"`
+	PaymentID        string             `bson:"payment_id,omitempty"`
+	OrderCode        string             `bson:"order_code"`
+	Provider         string             `bson:"provider"`
+	Amount           float64            `bson:"amount"`
+	GatewayReference string             `bson:"gateway_reference"`
+	PaymentStatus    PaymentStatus      `bson:"payment_status"`
+	Reason           string             `bson:"reason,omitempty"`
+	Status           OutboxStatus       `bson:"status"`
+	Attempts         int                `bson:"attempts"`
+	LastError        string             `bson:"last_error,omitempty"`
+	NextAttemptAt    time.Time          `bson:"next_attempt_at"`
+	DispatchedAt     *time.Time         `bson:"dispatched_at,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at"`
+}
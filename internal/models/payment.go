@@ -20,26 +20,62 @@ type PaymentMethod string
 
 const (
 	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+	PaymentMethodCOD          PaymentMethod = "cod"
+	// PaymentMethodOnline is settled through a provider's hosted checkout,
+	// never by a transfer showing up on our own bank statements.
+	PaymentMethodOnline PaymentMethod = "online"
 )
 
 type GatewayType string
 
 const (
 	GatewayTypeZalopay GatewayType = "zalopay"
+	GatewayTypeCOD     GatewayType = "cod"
+	GatewayTypeMock    GatewayType = "mock"
 )
 
 type Payment struct {
 	ID               primitive.ObjectID `bson:"_id,omitempty"`
 	OrderID          string             `bson:"order_id"`
+	OrderCode        string             `bson:"order_code"`
 	UserID           string             `bson:"user_id"`
 	Amount           float64            `bson:"amount"`
 	Currency         string             `bson:"currency"`
 	Status           PaymentStatus      `bson:"status"`
 	Method           PaymentMethod      `bson:"method"`
+	Provider         GatewayType        `bson:"provider"`
 	GatewayReference string             `bson:"gateway_reference"`
 	Description      string             `bson:"description"`
 	Metadata         map[string]string  `bson:"metadata,omitempty"`
+	CollectedAmount  float64            `bson:"collected_amount,omitempty"`
+	RefundedAmount   float64            `bson:"refunded_amount,omitempty"`
+	Refunds          []PaymentRefund    `bson:"refunds,omitempty"`
+	Routing          *RoutingDecision   `bson:"routing,omitempty"`
 	CreatedAt        time.Time          `bson:"created_at"`
 	UpdatedAt        time.Time          `bson:"updated_at"`
 	DeletedAt        *time.Time         `bson:"deleted_at,omitempty"`
 }
+
+// PaymentRefund is one refund of a payment. ID is ours and stays the same
+// when the refund is retried; Status is pending until the provider accepted
+// it, and GatewayReference is the provider's ID for it.
+type PaymentRefund struct {
+	ID               string        `bson:"id"`
+	Amount           float64       `bson:"amount"`
+	Reason           string        `bson:"reason,omitempty"`
+	Status           PaymentStatus `bson:"status"`
+	GatewayReference string        `bson:"gateway_reference,omitempty"`
+	CreatedAt        time.Time     `bson:"created_at"`
+}
+
+// RoutingDecision records how the gateway for a payment was chosen.
+type RoutingDecision struct {
+	Explicit   bool             `bson:"explicit"`
+	Candidates []GatewayType    `bson:"candidates"`
+	Attempts   []RoutingAttempt `bson:"attempts"`
+}
+
+type RoutingAttempt struct {
+	Provider GatewayType `bson:"provider"`
+	Error    string      `bson:"error,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StatementFormat string
+
+const (
+	StatementFormatCAMT053 StatementFormat = "camt053"
+	StatementFormatMT940   StatementFormat = "mt940"
+	StatementFormatCSV     StatementFormat = "csv"
+)
+
+type ReviewReason string
+
+const (
+	ReviewReasonFuzzyReference ReviewReason = "fuzzy_reference"
+	ReviewReasonAmountMismatch ReviewReason = "amount_mismatch"
+	ReviewReasonAmbiguous      ReviewReason = "ambiguous"
+)
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusResolved ReviewStatus = "resolved"
+)
+
+// StatementReview is a bank statement transaction that could not be matched
+// to a pending payment with enough confidence and needs a human decision.
+type StatementReview struct {
+	ID                primitive.ObjectID   `bson:"_id,omitempty"`
+	TransactionID     string               `bson:"transaction_id"`
+	Format            StatementFormat      `bson:"format"`
+	BookingDate       time.Time            `bson:"booking_date"`
+	Amount            float64              `bson:"amount"`
+	Currency          string               `bson:"currency"`
+	Reference         string               `bson:"reference"`
+	Description       string               `bson:"description"`
+	Counterparty      string               `bson:"counterparty"`
+	Reason            ReviewReason         `bson:"reason"`
+	CandidatePayments []primitive.ObjectID `bson:"candidate_payments"`
+	Status            ReviewStatus         `bson:"status"`
+	CreatedAt         time.Time            `bson:"created_at"`
+	UpdatedAt         time.Time            `bson:"updated_at"`
+}
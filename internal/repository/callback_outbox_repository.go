@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Enqueue keys entries by Key, so a callback delivered again while its entry
+// is queued returns the existing entry unchanged.
+func (r *implCallbackOutboxRepository) Enqueue(ctx context.Context, e models.CallbackOutboxEntry) (models.CallbackOutboxEntry, error) {
+	now := time.Now()
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"key":               e.Key,
+			"payment_id":        e.PaymentID,
+			"order_code":        e.OrderCode,
+			"provider":          e.Provider,
+			"amount":            e.Amount,
+			"gateway_reference": e.GatewayReference,
+			"payment_status":    e.PaymentStatus,
+			"reason":            e.Reason,
+			"status":            models.OutboxStatusPending,
+			"attempts":          0,
+			"next_attempt_at":   now,
+			"created_at":        now,
+			"updated_at":        now,
+		},
+	}
+
+	filter := bson.M{"key": e.Key}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var out models.CallbackOutboxEntry
+	err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&out)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent duplicate inserted it first; this now returns theirs.
+		err = r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&out)
+	}
+	if err != nil {
+		return models.CallbackOutboxEntry{}, err
+	}
+
+	return out, nil
+}
+
+// Claim takes a due pending entry for one attempt, hiding it from other
+// dispatchers until until. It returns ErrNotFound when the entry is not due.
+func (r *implCallbackOutboxRepository) Claim(ctx context.Context, id primitive.ObjectID, until time.Time) (models.CallbackOutboxEntry, error) {
+	return r.claim(ctx, bson.M{"_id": id}, until)
+}
+
+// ClaimNext claims the entry due the longest, or returns ErrNotFound.
+func (r *implCallbackOutboxRepository) ClaimNext(ctx context.Context, until time.Time) (models.CallbackOutboxEntry, error) {
+	return r.claim(ctx, bson.M{}, until, options.FindOneAndUpdate().SetSort(bson.M{"next_attempt_at": 1}))
+}
+
+func (r *implCallbackOutboxRepository) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	return r.update(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"status":        models.OutboxStatusDispatched,
+			"dispatched_at": now,
+			"updated_at":    now,
+		},
+		"$unset": bson.M{"last_error": ""},
+	})
+}
+
+func (r *implCallbackOutboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, opts MarkFailedOptions) error {
+	set := bson.M{
+		"last_error":      opts.Error,
+		"next_attempt_at": opts.NextAttemptAt,
+		"updated_at":      time.Now(),
+	}
+	if opts.DeadLetter {
+		set["status"] = models.OutboxStatusDeadLetter
+	}
+
+	return r.update(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+}
+
+func (r *implCallbackOutboxRepository) List(ctx context.Context, opts ListOutboxOptions) ([]models.CallbackOutboxEntry, error) {
+	filter := bson.M{}
+	if opts.Status != "" {
+		filter["status"] = opts.Status
+	}
+
+	findOpts := options.Find().SetSort(bson.M{"created_at": 1})
+	if opts.Limit > 0 {
+		findOpts.SetLimit(opts.Limit)
+	}
+
+	cur, err := r.col.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var es []models.CallbackOutboxEntry
+	if err := cur.All(ctx, &es); err != nil {
+		return nil, err
+	}
+
+	return es, nil
+}
+
+// Redrive puts a dead-lettered entry back in the queue with a fresh attempt
+// budget. Entries in any other status return ErrStatusConflict.
+func (r *implCallbackOutboxRepository) Redrive(ctx context.Context, id primitive.ObjectID) (models.CallbackOutboxEntry, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":          models.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		},
+	}
+
+	var e models.CallbackOutboxEntry
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"_id": id, "status": models.OutboxStatusDeadLetter}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&e)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if n, err := r.col.CountDocuments(ctx, bson.M{"_id": id}); err == nil && n > 0 {
+				return models.CallbackOutboxEntry{}, ErrStatusConflict
+			}
+			return models.CallbackOutboxEntry{}, ErrNotFound
+		}
+		return models.CallbackOutboxEntry{}, err
+	}
+
+	return e, nil
+}
+
+func (r *implCallbackOutboxRepository) claim(ctx context.Context, filter bson.M, until time.Time, opts ...*options.FindOneAndUpdateOptions) (models.CallbackOutboxEntry, error) {
+	filter["status"] = models.OutboxStatusPending
+	filter["next_attempt_at"] = bson.M{"$lte": time.Now()}
+	update := bson.M{
+		"$set": bson.M{"next_attempt_at": until, "updated_at": time.Now()},
+		"$inc": bson.M{"attempts": 1},
+	}
+
+	var e models.CallbackOutboxEntry
+	err := r.col.FindOneAndUpdate(ctx, filter, update,
+		append(opts, options.FindOneAndUpdate().SetReturnDocument(options.After))...).Decode(&e)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.CallbackOutboxEntry{}, ErrNotFound
+		}
+		return models.CallbackOutboxEntry{}, err
+	}
+
+	return e, nil
+}
+
+func (r *implCallbackOutboxRepository) update(ctx context.Context, filter bson.M, update bson.M) error {
+	res, err := r.col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound       = errors.New("record not found")
+	ErrStatusConflict = errors.New("record status changed")
+	ErrAmountExceeded = errors.New("amount exceeds record total")
+)
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// uniqueIndexes back the upserts that deduplicate by a natural key: without
+// them two concurrent upserts of the same key can both insert.
+var uniqueIndexes = map[string]string{
+	statementReviewCollection: "transaction_id",
+	callbackOutboxCollection:  "key",
+}
+
+// EnsureIndexes creates the collections' indexes. It is safe to call on
+// every start; existing indexes are left as they are.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	for col, field := range uniqueIndexes {
+		if _, err := db.Collection(col).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: field, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}); err != nil {
+			return fmt.Errorf("failed to index %s.%s: %w", col, field, err)
+		}
+	}
+	return nil
+}
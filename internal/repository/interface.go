@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, p models.Payment) (models.Payment, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Payment, error)
+	FindByOrderCode(ctx context.Context, orderCode string) (models.Payment, error)
+	FindByGatewayReference(ctx context.Context, ref string) (models.Payment, error)
+	List(ctx context.Context, opts ListPaymentsOptions) ([]models.Payment, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, opts UpdateStatusOptions) (models.Payment, error)
+	ReserveRefund(ctx context.Context, id primitive.ObjectID, rf models.PaymentRefund) (models.Payment, error)
+	ReleaseRefund(ctx context.Context, id primitive.ObjectID, refundID string) error
+	RecordRefund(ctx context.Context, id primitive.ObjectID, refundID string, gatewayRef string) (models.Payment, error)
+}
+
+type StatementReviewRepository interface {
+	Upsert(ctx context.Context, r models.StatementReview) (models.StatementReview, error)
+}
+
+type CallbackOutboxRepository interface {
+	Enqueue(ctx context.Context, e models.CallbackOutboxEntry) (models.CallbackOutboxEntry, error)
+	Claim(ctx context.Context, id primitive.ObjectID, until time.Time) (models.CallbackOutboxEntry, error)
+	ClaimNext(ctx context.Context, until time.Time) (models.CallbackOutboxEntry, error)
+	MarkDispatched(ctx context.Context, id primitive.ObjectID) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, opts MarkFailedOptions) error
+	List(ctx context.Context, opts ListOutboxOptions) ([]models.CallbackOutboxEntry, error)
+	Redrive(ctx context.Context, id primitive.ObjectID) (models.CallbackOutboxEntry, error)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import "go.mongodb.org/mongo-driver/mongo"
+
+const (
+	paymentCollection         = "payments"
+	statementReviewCollection = "statement_reviews"
+	callbackOutboxCollection  = "callback_outbox"
+)
+
+type implPaymentRepository struct {
+	col *mongo.Collection
+}
+
+func NewPaymentRepository(db *mongo.Database) PaymentRepository {
+	return &implPaymentRepository{
+		col: db.Collection(paymentCollection),
+	}
+}
+
+type implStatementReviewRepository struct {
+	col *mongo.Collection
+}
+
+func NewStatementReviewRepository(db *mongo.Database) StatementReviewRepository {
+	return &implStatementReviewRepository{
+		col: db.Collection(statementReviewCollection),
+	}
+}
+
+type implCallbackOutboxRepository struct {
+	col *mongo.Collection
+}
+
+func NewCallbackOutboxRepository(db *mongo.Database) CallbackOutboxRepository {
+	return &implCallbackOutboxRepository{
+		col: db.Collection(callbackOutboxCollection),
+	}
+}
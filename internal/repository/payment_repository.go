@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *implPaymentRepository) Create(ctx context.Context, p models.Payment) (models.Payment, error) {
+	now := time.Now()
+	p.ID = primitive.NewObjectID()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	if _, err := r.col.InsertOne(ctx, p); err != nil {
+		return models.Payment{}, err
+	}
+
+	return p, nil
+}
+
+func (r *implPaymentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Payment, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *implPaymentRepository) FindByOrderCode(ctx context.Context, orderCode string) (models.Payment, error) {
+	return r.findOne(ctx, bson.M{"order_code": orderCode}, options.FindOne().SetSort(bson.M{"created_at": -1}))
+}
+
+func (r *implPaymentRepository) FindByGatewayReference(ctx context.Context, ref string) (models.Payment, error) {
+	return r.findOne(ctx, bson.M{"gateway_reference": ref})
+}
+
+func (r *implPaymentRepository) List(ctx context.Context, opts ListPaymentsOptions) ([]models.Payment, error) {
+	filter := bson.M{"deleted_at": nil}
+	if opts.Status != "" {
+		filter["status"] = opts.Status
+	}
+	if opts.Method != "" {
+		filter["method"] = opts.Method
+	}
+
+	cur, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []models.Payment
+	if err := cur.All(ctx, &ps); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func (r *implPaymentRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, opts UpdateStatusOptions) (models.Payment, error) {
+	set := bson.M{
+		"status":     opts.Status,
+		"updated_at": time.Now(),
+	}
+	if opts.GatewayReference != "" {
+		set["gateway_reference"] = opts.GatewayReference
+	}
+	if opts.CollectedAmount > 0 {
+		set["collected_amount"] = opts.CollectedAmount
+	}
+	for k, v := range opts.Metadata {
+		set["metadata."+k] = v
+	}
+
+	filter := bson.M{"_id": id}
+	if len(opts.From) > 0 {
+		filter["status"] = bson.M{"$in": opts.From}
+	}
+
+	var p models.Payment
+	err := r.col.FindOneAndUpdate(ctx, filter, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&p)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if len(opts.From) > 0 {
+				if _, err := r.FindByID(ctx, id); err == nil {
+					return models.Payment{}, ErrStatusConflict
+				}
+			}
+			return models.Payment{}, ErrNotFound
+		}
+		return models.Payment{}, err
+	}
+
+	return p, nil
+}
+
+// ReserveRefund adds a pending refund to a completed payment and its amount
+// to the refunded amount, in the same update that checks the total stays
+// within the amount paid, so concurrent refunds cannot together exceed it.
+// A refund already on the payment is not added again; the payment is
+// returned as it is. It returns ErrStatusConflict for a payment that is not
+// completed and ErrAmountExceeded when the total would be too large.
+func (r *implPaymentRepository) ReserveRefund(ctx context.Context, id primitive.ObjectID, rf models.PaymentRefund) (models.Payment, error) {
+	filter := bson.M{
+		"_id":        id,
+		"status":     models.PaymentStatusCompleted,
+		"refunds.id": bson.M{"$ne": rf.ID},
+		"$expr": bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$refunded_amount", 0}}, rf.Amount}},
+			"$amount",
+		}},
+	}
+	update := bson.M{
+		"$inc":  bson.M{"refunded_amount": rf.Amount},
+		"$push": bson.M{"refunds": rf},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	var p models.Payment
+	err := r.col.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		cur, err := r.FindByID(ctx, id)
+		if err != nil {
+			return models.Payment{}, err
+		}
+		for _, existing := range cur.Refunds {
+			if existing.ID == rf.ID {
+				return cur, nil
+			}
+		}
+		if cur.Status != models.PaymentStatusCompleted {
+			return models.Payment{}, ErrStatusConflict
+		}
+		return models.Payment{}, ErrAmountExceeded
+	}
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return p, nil
+}
+
+// ReleaseRefund removes a pending refund that did not go through and gives
+// back its amount. A payment marked refunded by a refund that finished
+// meanwhile is no longer refunded in full, so it goes back to completed. A
+// refund that is not pending is left alone.
+func (r *implPaymentRepository) ReleaseRefund(ctx context.Context, id primitive.ObjectID, refundID string) error {
+	isRefund := bson.M{"$eq": bson.A{"$$r.id", refundID}}
+	_, err := r.col.UpdateOne(ctx, bson.M{
+		"_id":     id,
+		"refunds": bson.M{"$elemMatch": bson.M{"id": refundID, "status": models.PaymentStatusPending}},
+	}, bson.A{
+		bson.M{"$set": bson.M{
+			"refunded_amount": bson.M{"$subtract": bson.A{
+				bson.M{"$ifNull": bson.A{"$refunded_amount", 0}},
+				bson.M{"$sum": bson.M{"$map": bson.M{
+					"input": bson.M{"$filter": bson.M{"input": "$refunds", "as": "r", "cond": isRefund}},
+					"as":    "r",
+					"in":    "$$r.amount",
+				}}},
+			}},
+			"refunds": bson.M{"$filter": bson.M{"input": "$refunds", "as": "r", "cond": bson.M{"$not": bson.A{isRefund}}}},
+			"status": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$status", models.PaymentStatusRefunded}},
+				models.PaymentStatusCompleted,
+				"$status",
+			}},
+			"updated_at": time.Now(),
+		}},
+	})
+	return err
+}
+
+// RecordRefund marks a pending refund as accepted by the provider under
+// gatewayRef. The payment is refunded once its refunds cover the whole
+// amount paid.
+func (r *implPaymentRepository) RecordRefund(ctx context.Context, id primitive.ObjectID, refundID string, gatewayRef string) (models.Payment, error) {
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"refunds": bson.M{"$map": bson.M{
+				"input": "$refunds",
+				"as":    "r",
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$$r.id", refundID}},
+					bson.M{"$mergeObjects": bson.A{"$$r", bson.M{
+						"status":            models.PaymentStatusCompleted,
+						"gateway_reference": gatewayRef,
+					}}},
+					"$$r",
+				}},
+			}},
+			"status": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$refunded_amount", "$amount"}},
+				models.PaymentStatusRefunded,
+				"$status",
+			}},
+			"updated_at": time.Now(),
+		}},
+	}
+
+	var p models.Payment
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"_id": id, "refunds.id": refundID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Payment{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return p, nil
+}
+
+func (r *implPaymentRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (models.Payment, error) {
+	filter["deleted_at"] = nil
+
+	var p models.Payment
+	if err := r.col.FindOne(ctx, filter, opts...).Decode(&p); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Payment{}, ErrNotFound
+		}
+		return models.Payment{}, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Upsert keys reviews by bank transaction ID so re-importing the same
+// statement does not queue the same transaction twice.
+func (r *implStatementReviewRepository) Upsert(ctx context.Context, rv models.StatementReview) (models.StatementReview, error) {
+	now := time.Now()
+	rv.UpdatedAt = now
+
+	update := bson.M{
+		"$set": bson.M{
+			"format":             rv.Format,
+			"booking_date":       rv.BookingDate,
+			"amount":             rv.Amount,
+			"currency":           rv.Currency,
+			"reference":          rv.Reference,
+			"description":        rv.Description,
+			"counterparty":       rv.Counterparty,
+			"reason":             rv.Reason,
+			"candidate_payments": rv.CandidatePayments,
+			"updated_at":         now,
+		},
+		"$setOnInsert": bson.M{
+			"transaction_id": rv.TransactionID,
+			"status":         models.ReviewStatusPending,
+			"created_at":     now,
+		},
+	}
+
+	filter := bson.M{"transaction_id": rv.TransactionID}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var out models.StatementReview
+	err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&out)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent import inserted it first; this now updates theirs.
+		err = r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&out)
+	}
+	if err != nil {
+		return models.StatementReview{}, err
+	}
+
+	return out, nil
+}
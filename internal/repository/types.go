@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+)
+
+type ListPaymentsOptions struct {
+	Status models.PaymentStatus
+	Method models.PaymentMethod
+}
+
+// UpdateStatusOptions with From set only update a payment that is in one of
+// those statuses and return ErrStatusConflict otherwise, so concurrent
+// updaters can tell who made the transition.
+type UpdateStatusOptions struct {
+	Status           models.PaymentStatus
+	From             []models.PaymentStatus
+	GatewayReference string
+	CollectedAmount  float64
+	Metadata         map[string]string
+}
+
+// MarkFailedOptions reschedules a pending entry at NextAttemptAt, or moves
+// it to the dead letters when DeadLetter is set.
+type MarkFailedOptions struct {
+	Error         string
+	NextAttemptAt time.Time
+	DeadLetter    bool
+}
+
+type ListOutboxOptions struct {
+	Status models.OutboxStatus
+	Limit  int64
+}
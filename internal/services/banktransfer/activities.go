@@ -0,0 +1,176 @@
+package banktransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/product"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PaymentActivities expose the payment service's steps to Temporal, so
+// other services can orchestrate them instead of calling gRPC directly.
+type PaymentActivities struct {
+	svc        *implPaymentService
+	productSvc product.ProductServiceClient
+}
+
+// NewPaymentActivities takes the product service, which only compensation
+// calls.
+func NewPaymentActivities(svc payment.PaymentServiceServer, productSvc product.ProductServiceClient) (*PaymentActivities, error) {
+	impl, ok := svc.(*implPaymentService)
+	if !ok {
+		return nil, errors.New("invalid payment service implementation")
+	}
+	return &PaymentActivities{svc: impl, productSvc: productSvc}, nil
+}
+
+// RegisterPaymentWorker registers the payment workflows and activities.
+func RegisterPaymentWorker(w worker.Registry, acts *PaymentActivities) {
+	w.RegisterActivity(acts)
+	w.RegisterWorkflowWithOptions(SyncPaymentStatusWorkflow, workflowOptions(WorkflowSyncPaymentStatus))
+	w.RegisterWorkflowWithOptions(RefundPaymentWorkflow, workflowOptions(WorkflowRefundPayment))
+	w.RegisterWorkflowWithOptions(PollPaymentStatusWorkflow, workflowOptions(WorkflowPollPaymentStatus))
+	w.RegisterWorkflowWithOptions(CompensatePaymentWorkflow, workflowOptions(WorkflowCompensatePayment))
+}
+
+// PersistCallbackResult records a callback outcome on the payment, the same
+// way the HTTP callback does, without signalling the order's workflow. A
+// payment already in that status is left as it is.
+func (a *PaymentActivities) PersistCallbackResult(ctx context.Context, ev CallbackEvent) error {
+	_, err := a.svc.recordCallback(ctx, ev)
+	if errors.Is(err, ErrDuplicateCallback) {
+		return nil
+	}
+	return activityError(err)
+}
+
+// QueryGatewayStatus asks the gateway for the status of an order's payment.
+// The stored payment is left untouched.
+func (a *PaymentActivities) QueryGatewayStatus(ctx context.Context, params PaymentWorkflowParams) (models.PaymentStatus, error) {
+	p, err := a.svc.findPayment(ctx, params.OrderCode)
+	if err != nil {
+		return "", activityError(err)
+	}
+
+	st, err := a.svc.queryGatewayStatus(ctx, p)
+	return st, activityError(err)
+}
+
+// SettlePaymentStatus applies a final status the gateway reported for an
+// order's payment through the callback path, as the status poller does, so
+// the order's workflow is signalled, or compensation started, once.
+func (a *PaymentActivities) SettlePaymentStatus(ctx context.Context, params SettlePaymentParams) error {
+	p, err := a.svc.findPayment(ctx, params.OrderCode)
+	if err != nil {
+		return activityError(err)
+	}
+
+	a.svc.l.Infof(ctx, "payment %s %s according to status sync", p.ID.Hex(), params.Status)
+	return activityError(a.svc.applyGatewayStatus(ctx, p, params.Status))
+}
+
+// CheckPaymentStatus is one round of the status poller. A payment a
+// callback already settled is not queried; a settled query result goes
+// through the callback path, so the order's workflow is signalled once.
+func (a *PaymentActivities) CheckPaymentStatus(ctx context.Context, params PollPaymentParams) (PollResult, error) {
+	p, err := a.svc.findPaymentByID(ctx, params.PaymentID)
+	if err != nil {
+		return PollResult{}, activityError(err)
+	}
+	if p.Status != models.PaymentStatusPending {
+		return PollResult{Status: p.Status, Done: true}, nil
+	}
+
+	latest, err := a.svc.findPayment(ctx, p.OrderCode)
+	if err != nil {
+		return PollResult{}, activityError(err)
+	}
+	if latest.ID != p.ID {
+		a.svc.l.Infof(ctx, "payment %s replaced by %s, polling stopped", p.ID.Hex(), latest.ID.Hex())
+		return PollResult{Status: p.Status, Done: true}, nil
+	}
+
+	st, err := a.svc.queryGatewayStatus(ctx, p)
+	if err != nil {
+		return PollResult{}, activityError(err)
+	}
+	if st != models.PaymentStatusCompleted && st != models.PaymentStatusFailed {
+		return PollResult{Status: st}, nil
+	}
+
+	a.svc.l.Infof(ctx, "payment %s %s according to status poll", p.ID.Hex(), st)
+	if err := a.svc.applyGatewayStatus(ctx, p, st); err != nil {
+		return PollResult{}, activityError(err)
+	}
+
+	return PollResult{Status: st, Done: true}, nil
+}
+
+// applyGatewayStatus settles p with a final status from the gateway's status
+// API the way its callback would have. A payment a callback settled first is
+// left as it is.
+func (svc *implPaymentService) applyGatewayStatus(ctx context.Context, p models.Payment, st models.PaymentStatus) error {
+	ev := CallbackEvent{
+		OrderCode:        p.OrderCode,
+		Status:           st,
+		GatewayReference: p.GatewayReference,
+		Amount:           p.Amount,
+	}
+	if st == models.PaymentStatusFailed {
+		ev.Reason = "failed according to gateway status query"
+	}
+	if err := svc.applyCallback(ctx, ev); err != nil && !errors.Is(err, ErrDuplicateCallback) {
+		return err
+	}
+	return nil
+}
+
+func (a *PaymentActivities) UpdateOrderStatus(ctx context.Context, params OrderStatusParams) error {
+	if _, err := a.svc.orderSvc.UpdateStatus(withIdempotencyKey(ctx, params.IdempotencyKey), &order.UpdateStatusRequest{
+		Request: &order.UpdateStatusRequest_Code{Code: params.OrderCode},
+		Status:  params.Status,
+	}); err != nil {
+		a.svc.l.Errorf(ctx, "failed to update order %s to %s: %v", params.OrderCode, params.Status, err)
+		return activityError(err)
+	}
+	return nil
+}
+
+// IssueRefund refunds through the payment's gateway. The refund is keyed by
+// the workflow and its request, so a retry after the provider accepted it,
+// e.g. when the call timed out or recording it failed, finds the same
+// refund rather than refunding twice.
+func (a *PaymentActivities) IssueRefund(ctx context.Context, params RefundWorkflowParams) (*payment.RefundPaymentResponse, error) {
+	key := fmt.Sprintf("%s:%.0f:%s", activity.GetInfo(ctx).WorkflowExecution.ID, params.Amount, params.Reason)
+	res, err := a.svc.refund(ctx, &payment.RefundPaymentRequest{
+		OrderCode: params.OrderCode,
+		Amount:    params.Amount,
+		Reason:    params.Reason,
+	}, key)
+	return res, activityError(err)
+}
+
+// activityError stops Temporal from retrying errors a retry cannot fix.
+func activityError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrInvalidCallback) {
+		return temporal.NewNonRetryableApplicationError(err.Error(), codes.InvalidArgument.String(), err)
+	}
+
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition:
+		return temporal.NewNonRetryableApplicationError(status.Convert(err).Message(), status.Code(err).String(), err)
+	}
+	return err
+}
@@ -0,0 +1,481 @@
+package banktransfer_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay/zalopaytest"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/mocks"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testTemporalConfig = config.TemporalConfig{
+	PaymentTaskQueue:         "PAYMENT_TASK_QUEUE",
+	OrderTaskQueue:           "POST_PAYMENT_ORDER_TASK_QUEUE",
+	WorkflowExecutionTimeout: 24 * time.Hour,
+	WorkflowRunTimeout:       24 * time.Hour,
+	WorkflowTaskTimeout:      time.Minute,
+	PollInitialInterval:      30 * time.Second,
+	PollMaxInterval:          5 * time.Minute,
+	PollTimeout:              30 * time.Minute,
+}
+
+// Retries are due after testRetryInterval, so tests can drain the outbox
+// without waiting long.
+var testOutboxConfig = config.OutboxConfig{
+	DispatchInterval:     time.Second,
+	BatchSize:            10,
+	MaxAttempts:          3,
+	RetryInitialInterval: testRetryInterval,
+	RetryMaxInterval:     testRetryInterval,
+	Lease:                time.Minute,
+}
+
+const testRetryInterval = 50 * time.Millisecond
+
+// dispatchDue runs the dispatcher once retries are due.
+func dispatchDue(d *bankTf.CallbackDispatcher) int {
+	time.Sleep(testRetryInterval + 10*time.Millisecond)
+	return d.DispatchDue(context.Background())
+}
+
+// callbackEnv wires the payment service to a fake ZaloPay, a fake order
+// service over bufconn and a mocked Temporal client. ZaloPay's callbacks go
+// through HandlePaymentCallback like in cmd/http.
+type callbackEnv struct {
+	svc        payment.PaymentServiceServer
+	gwf        *bankTf.GatewayFactory
+	temporal   *mocks.Client
+	zalopay    *zalopaytest.Server
+	orders     *fakeOrderService
+	products   *fakeProductService
+	productSvc product.ProductServiceClient
+	payments   *memPayments
+	reviews    *memReviews
+	outbox     *memOutbox
+}
+
+func newCallbackEnv(t *testing.T) *callbackEnv {
+	t.Helper()
+
+	zp := zalopaytest.NewServer(2553, "test-key1", "test-key2")
+	t.Cleanup(zp.Close)
+	gw, err := zp.Gateway("")
+	require.NoError(t, err)
+
+	gwf := bankTf.NewPaymentGatewayFactory()
+	require.NoError(t, gwf.RegisterGateway(models.GatewayTypeZalopay, gw))
+	health := bankTf.NewGatewayHealth(config.CircuitBreakerConfig{
+		WindowSize:     20,
+		MinRequests:    5,
+		FailureRate:    0.5,
+		SlowCall:       5 * time.Second,
+		OpenTimeout:    30 * time.Second,
+		HalfOpenProbes: 1,
+	})
+	router := bankTf.NewGatewayRouter(gwf, []config.RoutingRule{{Provider: string(models.GatewayTypeZalopay), Weight: 100}}, health)
+
+	orders, products, orderSvc, productSvc := startOrderService(t)
+	env := &callbackEnv{
+		gwf:        gwf,
+		temporal:   mocks.NewClient(t),
+		zalopay:    zp,
+		orders:     orders,
+		products:   products,
+		productSvc: productSvc,
+		payments:   &memPayments{},
+		reviews:    &memReviews{},
+		outbox:     &memOutbox{},
+	}
+	env.svc = bankTf.NewPaymentService(testLogger(), gwf, router, health, orderSvc, env.temporal, testTemporalConfig, testOutboxConfig,
+		env.payments, env.reviews, env.outbox, statement.CSVLayout{})
+
+	cb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bankTf.HandlePaymentCallback(env.svc, r.Context(), models.GatewayTypeZalopay, w, r)
+	}))
+	t.Cleanup(cb.Close)
+	gw.Host = cb.URL
+
+	return env
+}
+
+// createPayment opens a ZaloPay payment for a new order and returns its
+// app_trans_id. The status poll it starts is expected on the mock.
+func (e *callbackEnv) createPayment(t *testing.T, orderCode string, amount float64) string {
+	t.Helper()
+
+	e.orders.addOrder(&order.OrderData{
+		Id:          "id-" + orderCode,
+		Code:        orderCode,
+		Status:      order.OrderStatus_PAYMENT_PENDING,
+		TotalAmount: amount,
+		Items:       []*order.OrderItem{{ProductId: "p1", Quantity: 2}},
+	})
+	e.temporal.On("ExecuteWorkflow", mock.Anything, mock.MatchedBy(func(o client.StartWorkflowOptions) bool {
+		return o.TaskQueue == testTemporalConfig.PaymentTaskQueue
+	}), bankTf.WorkflowPollPaymentStatus, mock.Anything).Return(workflowRun("poll"), nil).Once()
+
+	_, err := e.svc.ProcessPayment(context.Background(), &payment.ProcessPaymentRequest{
+		OrderCode: orderCode,
+		UserId:    "u1",
+		Amount:    amount,
+		Provider:  string(models.GatewayTypeZalopay),
+	})
+	require.NoError(t, err)
+
+	o, ok := e.zalopay.OrderByCode(orderCode)
+	require.True(t, ok, "order %s not created at ZaloPay", orderCode)
+	return o.AppTransID
+}
+
+func (e *callbackEnv) payment(t *testing.T, orderCode string) models.Payment {
+	t.Helper()
+
+	p, err := e.payments.FindByOrderCode(context.Background(), orderCode)
+	require.NoError(t, err)
+	return p
+}
+
+func workflowRun(id string) *mocks.WorkflowRun {
+	run := &mocks.WorkflowRun{}
+	run.On("GetID").Return(id).Maybe()
+	run.On("GetRunID").Return(id + "-run").Maybe()
+	return run
+}
+
+func TestHandleCallbackSignalsPrePaymentWorkflow(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-1", 50000)
+
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-1", "", bankTf.SignalNamePaymentCompleted,
+		mock.MatchedBy(func(sig bankTf.PaymentSignal) bool {
+			return sig.OrderCode == "ORD-1" && sig.Status == models.PaymentStatusCompleted && sig.Amount == 50000
+		})).Return(nil).Once()
+
+	require.NoError(t, env.zalopay.Pay(transID))
+
+	p := env.payment(t, "ORD-1")
+	require.Equal(t, models.PaymentStatusCompleted, p.Status)
+	require.Equal(t, string(models.PaymentStatusCompleted), p.Metadata["workflow_signalled"])
+	require.Len(t, env.outbox.all(), 1)
+	require.Equal(t, models.OutboxStatusDispatched, env.outbox.all()[0].Status)
+
+	// A redelivered callback is acknowledged without a second signal.
+	require.NoError(t, env.zalopay.Redeliver(transID))
+	cbs := env.zalopay.Callbacks()
+	require.Equal(t, 1, cbs[len(cbs)-1].ReturnCode)
+}
+
+func TestHandleCallbackStartsPostPaymentWorkflow(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-2", 75000)
+
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-2", "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(serviceerror.NewNotFound("workflow not found")).Once()
+
+	var (
+		opts     client.StartWorkflowOptions
+		sig      bankTf.PaymentSignal
+		wfParams bankTf.OrderWorkflowParams
+	)
+	env.temporal.On("SignalWithStartWorkflow", mock.Anything, bankTf.WorkflowPostPaymentPrefix+"ORD-2", bankTf.SignalNamePaymentCompleted,
+		mock.Anything, mock.Anything, bankTf.WorkflowName, mock.Anything).
+		Run(func(args mock.Arguments) {
+			sig = args.Get(3).(bankTf.PaymentSignal)
+			opts = args.Get(4).(client.StartWorkflowOptions)
+			wfParams = args.Get(6).(bankTf.OrderWorkflowParams)
+		}).
+		Return(workflowRun(bankTf.WorkflowPostPaymentPrefix+"ORD-2"), nil).Once()
+
+	require.NoError(t, env.zalopay.Pay(transID))
+
+	require.Equal(t, testTemporalConfig.OrderTaskQueue, opts.TaskQueue)
+	require.Equal(t, bankTf.WorkflowPostPaymentPrefix+"ORD-2", opts.ID)
+	require.Equal(t, "ORD-2", wfParams.OrderCode)
+	require.Equal(t, env.payment(t, "ORD-2").ID.Hex(), sig.PaymentID)
+	require.Equal(t, string(models.GatewayTypeZalopay), sig.Provider)
+	require.Equal(t, 75000.0, sig.Amount)
+}
+
+func TestHandleCallbackRetriesFailedPostPaymentStart(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-9", 15000)
+
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-9", "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(serviceerror.NewNotFound("workflow not found")).Twice()
+	env.temporal.On("SignalWithStartWorkflow", mock.Anything, bankTf.WorkflowPostPaymentPrefix+"ORD-9", bankTf.SignalNamePaymentCompleted,
+		mock.Anything, mock.Anything, bankTf.WorkflowName, mock.Anything).
+		Return(nil, serviceerror.NewUnavailable("temporal unavailable")).Once()
+
+	// The start failing leaves the callback queued, not lost.
+	require.NoError(t, env.zalopay.Pay(transID))
+	entries := env.outbox.all()
+	require.Len(t, entries, 1)
+	require.Equal(t, models.OutboxStatusPending, entries[0].Status)
+	require.Contains(t, entries[0].LastError, "temporal unavailable")
+	require.Empty(t, env.payment(t, "ORD-9").Metadata["workflow_signalled"])
+
+	d, err := bankTf.NewCallbackDispatcher(env.svc)
+	require.NoError(t, err)
+	env.temporal.On("SignalWithStartWorkflow", mock.Anything, bankTf.WorkflowPostPaymentPrefix+"ORD-9", bankTf.SignalNamePaymentCompleted,
+		mock.Anything, mock.Anything, bankTf.WorkflowName, mock.Anything).
+		Return(workflowRun(bankTf.WorkflowPostPaymentPrefix+"ORD-9"), nil).Once()
+	require.Equal(t, 1, dispatchDue(d))
+
+	require.Equal(t, models.OutboxStatusDispatched, env.outbox.all()[0].Status)
+	require.Equal(t, string(models.PaymentStatusCompleted), env.payment(t, "ORD-9").Metadata["workflow_signalled"])
+}
+
+func TestHandleCallbackDuplicatePostPaymentStart(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-3", 10000)
+
+	env.temporal.On("SignalWorkflow", mock.Anything, mock.Anything, "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(serviceerror.NewNotFound("workflow not found")).Once()
+	env.temporal.On("SignalWithStartWorkflow", mock.Anything, bankTf.WorkflowPostPaymentPrefix+"ORD-3", mock.Anything,
+		mock.Anything, mock.Anything, bankTf.WorkflowName, mock.Anything).
+		Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "")).Once()
+
+	require.NoError(t, env.zalopay.Pay(transID))
+
+	require.Equal(t, models.OutboxStatusDispatched, env.outbox.all()[0].Status)
+	require.Equal(t, string(models.PaymentStatusCompleted), env.payment(t, "ORD-3").Metadata["workflow_signalled"])
+}
+
+func TestHandleCallbackQueuesSignalWhileTemporalIsDown(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-4", 20000)
+
+	down := serviceerror.NewUnavailable("temporal unavailable")
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-4", "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(down).Once()
+
+	// The callback is acknowledged: it is safe in the outbox.
+	require.NoError(t, env.zalopay.Pay(transID))
+	require.Len(t, env.zalopay.Callbacks(), 1)
+	require.Equal(t, 1, env.zalopay.Callbacks()[0].ReturnCode)
+
+	entries := env.outbox.all()
+	require.Len(t, entries, 1)
+	require.Equal(t, models.OutboxStatusPending, entries[0].Status)
+	require.Equal(t, 1, entries[0].Attempts)
+	require.Contains(t, entries[0].LastError, "temporal unavailable")
+	require.Equal(t, models.PaymentStatusCompleted, env.payment(t, "ORD-4").Status)
+
+	d, err := bankTf.NewCallbackDispatcher(env.svc)
+	require.NoError(t, err)
+
+	// Still down: the second attempt fails, the third dead-letters it.
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-4", "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(down).Twice()
+	require.Equal(t, 1, dispatchDue(d))
+	require.Equal(t, 1, dispatchDue(d))
+	dead, err := d.List(context.Background(), models.OutboxStatusDeadLetter, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Zero(t, dispatchDue(d))
+
+	// Back up: an operator re-drives the dead letter.
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-4", "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(nil).Once()
+	e, err := d.Redrive(context.Background(), dead[0].ID.Hex())
+	require.NoError(t, err)
+	require.Equal(t, models.OutboxStatusDispatched, e.Status)
+	require.Equal(t, string(models.PaymentStatusCompleted), env.payment(t, "ORD-4").Metadata["workflow_signalled"])
+
+	_, err = d.Redrive(context.Background(), dead[0].ID.Hex())
+	require.ErrorIs(t, err, bankTf.ErrOutboxEntryNotDead)
+}
+
+func TestHandleCallbackRejectsInvalidSignature(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-5", 30000)
+
+	env.zalopay.Key2 = "wrong-key2"
+	require.ErrorIs(t, env.zalopay.Pay(transID), zalopaytest.ErrNotAcknowledged)
+
+	require.Equal(t, models.PaymentStatusPending, env.payment(t, "ORD-5").Status)
+	require.Empty(t, env.outbox.all())
+}
+
+func TestHandleCallbackRejectsAmountMismatch(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-10", 50000)
+
+	// ZaloPay reports the 50000 it took for a payment now stored at 60000.
+	env.payments.mu.Lock()
+	env.payments.payments[0].Amount = 60000
+	env.payments.mu.Unlock()
+
+	require.ErrorIs(t, env.zalopay.Pay(transID), zalopaytest.ErrNotAcknowledged)
+	require.Equal(t, models.PaymentStatusPending, env.payment(t, "ORD-10").Status)
+	require.Empty(t, env.outbox.all())
+}
+
+// A query ZaloPay rejects, here for its MAC, says nothing about the order:
+// it is retried rather than read as a failed payment.
+func TestRefreshStatusRetriesRejectedQuery(t *testing.T) {
+	env := newCallbackEnv(t)
+	env.createPayment(t, "ORD-7", 30000)
+
+	req := &payment.GetPaymentStatusRequest{
+		PaymentIdentifier: &payment.GetPaymentStatusRequest_OrderCode{OrderCode: "ORD-7"},
+		Refresh:           true,
+	}
+
+	env.zalopay.Key1 = "wrong-key1"
+	_, err := env.svc.GetPaymentStatus(context.Background(), req)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	env.zalopay.Key1 = "test-key1"
+	res, err := env.svc.GetPaymentStatus(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, payment.PaymentStatus_PAYMENT_STATUS_PENDING, res.Payment.Status)
+}
+
+// ZaloPay sends no callback for failed payments: the status poll finds
+// them, and the compensation it starts releases the order.
+func TestPolledFailureCompensatesOrder(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-6", 40000)
+	require.NoError(t, env.zalopay.Fail(transID))
+
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-6", "", bankTf.SignalNamePaymentFailed, mock.Anything).
+		Return(serviceerror.NewNotFound("workflow not found")).Once()
+	var params bankTf.CompensationParams
+	env.temporal.On("ExecuteWorkflow", mock.Anything, mock.MatchedBy(func(o client.StartWorkflowOptions) bool {
+		return o.ID == bankTf.WorkflowCompensationPrefix+"ORD-6"
+	}), bankTf.WorkflowCompensatePayment, mock.Anything).
+		Run(func(args mock.Arguments) { params = args.Get(3).(bankTf.CompensationParams) }).
+		Return(workflowRun(bankTf.WorkflowCompensationPrefix+"ORD-6"), nil).Once()
+
+	acts, err := bankTf.NewPaymentActivities(env.svc, env.productSvc)
+	require.NoError(t, err)
+	p := env.payment(t, "ORD-6")
+
+	var s testsuite.WorkflowTestSuite
+	pollEnv := s.NewTestWorkflowEnvironment()
+	bankTf.RegisterPaymentWorker(pollEnv, acts)
+	pollEnv.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, bankTf.PollPaymentParams{
+		PaymentID:       p.ID.Hex(),
+		OrderCode:       "ORD-6",
+		InitialInterval: testTemporalConfig.PollInitialInterval,
+		MaxInterval:     testTemporalConfig.PollMaxInterval,
+		ExpiresAt:       pollEnv.Now().Add(testTemporalConfig.PollTimeout),
+	})
+
+	require.NoError(t, pollEnv.GetWorkflowError())
+	var st models.PaymentStatus
+	require.NoError(t, pollEnv.GetWorkflowResult(&st))
+	require.Equal(t, models.PaymentStatusFailed, st)
+	require.Equal(t, models.PaymentStatusFailed, env.payment(t, "ORD-6").Status)
+	require.Equal(t, "ORD-6", params.OrderCode)
+	require.Equal(t, p.ID.Hex(), params.PaymentID)
+
+	compEnv := s.NewTestWorkflowEnvironment()
+	bankTf.RegisterPaymentWorker(compEnv, acts)
+	compEnv.ExecuteWorkflow(bankTf.WorkflowCompensatePayment, params)
+
+	require.NoError(t, compEnv.GetWorkflowError())
+	var res bankTf.CompensationResult
+	require.NoError(t, compEnv.GetWorkflowResult(&res))
+	require.Equal(t, []string{bankTf.CompensationStepFailOrder, bankTf.CompensationStepReleaseInventory}, res.Completed)
+
+	updates := env.orders.statusUpdates()
+	require.Len(t, updates, 1)
+	require.Equal(t, order.OrderStatus_PAYMENT_FAILED, updates[0].Status)
+	require.Equal(t, "payment-compensation:ORD-6:fail_order", updates[0].IdempotencyKey)
+
+	releases := env.products.inventoryReleases()
+	require.Len(t, releases, 1)
+	require.Equal(t, "p1", releases[0].Items[0].ProductId)
+	require.EqualValues(t, 2, releases[0].Items[0].Quantity)
+	require.Equal(t, "payment-compensation:ORD-6:release_inventory", releases[0].IdempotencyKey)
+	require.Equal(t, "completed", env.payment(t, "ORD-6").Metadata["compensation"])
+}
+
+// A failure found by a status sync is settled like a callback: the order is
+// left to compensation, which releases its inventory, rather than updated
+// directly.
+func TestSyncedFailureStartsCompensation(t *testing.T) {
+	env := newCallbackEnv(t)
+	transID := env.createPayment(t, "ORD-8", 40000)
+	require.NoError(t, env.zalopay.Fail(transID))
+
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-8", "", bankTf.SignalNamePaymentFailed, mock.Anything).
+		Return(serviceerror.NewNotFound("workflow not found")).Once()
+	var params bankTf.CompensationParams
+	env.temporal.On("ExecuteWorkflow", mock.Anything, mock.MatchedBy(func(o client.StartWorkflowOptions) bool {
+		return o.ID == bankTf.WorkflowCompensationPrefix+"ORD-8"
+	}), bankTf.WorkflowCompensatePayment, mock.Anything).
+		Run(func(args mock.Arguments) { params = args.Get(3).(bankTf.CompensationParams) }).
+		Return(workflowRun(bankTf.WorkflowCompensationPrefix+"ORD-8"), nil).Once()
+
+	acts, err := bankTf.NewPaymentActivities(env.svc, env.productSvc)
+	require.NoError(t, err)
+	var s testsuite.WorkflowTestSuite
+	syncEnv := s.NewTestWorkflowEnvironment()
+	bankTf.RegisterPaymentWorker(syncEnv, acts)
+	syncEnv.ExecuteWorkflow(bankTf.WorkflowSyncPaymentStatus, bankTf.PaymentWorkflowParams{OrderCode: "ORD-8"})
+
+	require.NoError(t, syncEnv.GetWorkflowError())
+	var st models.PaymentStatus
+	require.NoError(t, syncEnv.GetWorkflowResult(&st))
+	require.Equal(t, models.PaymentStatusFailed, st)
+
+	p := env.payment(t, "ORD-8")
+	require.Equal(t, models.PaymentStatusFailed, p.Status)
+	require.Equal(t, string(models.PaymentStatusFailed), p.Metadata["workflow_signalled"])
+	require.Equal(t, p.ID.Hex(), params.PaymentID)
+	require.Empty(t, env.orders.statusUpdates())
+	require.Len(t, env.outbox.all(), 1)
+}
+
+// A poll for a payment that does not exist fails for good on the first
+// attempt instead of using up its retries.
+func TestPollStopsRetryingMissingPayment(t *testing.T) {
+	env := newCallbackEnv(t)
+	acts, err := bankTf.NewPaymentActivities(env.svc, env.productSvc)
+	require.NoError(t, err)
+
+	var s testsuite.WorkflowTestSuite
+	pollEnv := s.NewTestWorkflowEnvironment()
+	bankTf.RegisterPaymentWorker(pollEnv, acts)
+	attempts := 0
+	pollEnv.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+		attempts++
+	})
+	pollEnv.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, bankTf.PollPaymentParams{
+		PaymentID:       primitive.NewObjectID().Hex(),
+		OrderCode:       "ORD-MISSING",
+		InitialInterval: testTemporalConfig.PollInitialInterval,
+		MaxInterval:     testTemporalConfig.PollMaxInterval,
+		ExpiresAt:       pollEnv.Now().Add(testTemporalConfig.PollTimeout),
+	})
+
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(pollEnv.GetWorkflowError(), &appErr))
+	require.Equal(t, codes.NotFound.String(), appErr.Type())
+	require.Equal(t, 1, attempts)
+}
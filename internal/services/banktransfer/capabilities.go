@@ -0,0 +1,323 @@
+package banktransfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (svc *implPaymentService) GetPaymentStatus(ctx context.Context, req *payment.GetPaymentStatusRequest) (*payment.GetPaymentStatusResponse, error) {
+	var (
+		p   models.Payment
+		err error
+	)
+	if id := req.GetPaymentId(); id != "" {
+		p, err = svc.findPaymentByID(ctx, id)
+	} else {
+		p, err = svc.findPayment(ctx, req.GetOrderCode())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Refresh {
+		st, err := svc.queryGatewayStatus(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		p.Status = st
+	}
+
+	return &payment.GetPaymentStatusResponse{Payment: toPaymentData(p)}, nil
+}
+
+func (svc *implPaymentService) queryGatewayStatus(ctx context.Context, p models.Payment) (models.PaymentStatus, error) {
+	gw, err := svc.gwf.GetGateway(p.Provider)
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to get payment gateway: %v", err)
+		return "", status.Error(codes.Internal, ErrInternal.Error())
+	}
+
+	sq, ok := gw.(StatusQuerier)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", p.Provider, ErrStatusQueryNotSupported)
+		return "", status.Errorf(codes.FailedPrecondition, "gateway %s: %v", p.Provider, ErrStatusQueryNotSupported)
+	}
+
+	var st models.PaymentStatus
+	if err := svc.health.Do(p.Provider, func() error {
+		var qErr error
+		st, qErr = sq.QueryPaymentStatus(ctx, p)
+		return qErr
+	}); err != nil {
+		return "", svc.gatewayError(ctx, "query payment status", err)
+	}
+
+	return st, nil
+}
+
+// RefundPayment refunds once per idempotency-key sent as gRPC metadata; a
+// call without one is always a new refund.
+func (svc *implPaymentService) RefundPayment(ctx context.Context, req *payment.RefundPaymentRequest) (*payment.RefundPaymentResponse, error) {
+	key := primitive.NewObjectID().Hex()
+	if vals := metadata.ValueFromIncomingContext(ctx, idempotencyKeyHeader); len(vals) > 0 && vals[0] != "" {
+		key = vals[0]
+	}
+	return svc.refund(ctx, req, key)
+}
+
+// refund issues the refund key identifies. Repeating it returns the refund
+// already made, and a refund left pending by a failed attempt is sent to the
+// provider again under the same ID.
+func (svc *implPaymentService) refund(ctx context.Context, req *payment.RefundPaymentRequest, key string) (*payment.RefundPaymentResponse, error) {
+	p, err := svc.findPayment(ctx, req.OrderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	id := refundID(p.ID.Hex(), key)
+	rf, ok := findRefund(p, id)
+	if ok && rf.Status == models.PaymentStatusCompleted {
+		return toRefundResponse(p, rf), nil
+	}
+	if !ok {
+		if p.Status != models.PaymentStatusCompleted {
+			svc.l.Warnf(ctx, "payment %s is %s: %v", p.ID.Hex(), p.Status, ErrPaymentNotCompleted)
+			return nil, status.Error(codes.FailedPrecondition, ErrPaymentNotCompleted.Error())
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			amount = p.Amount - p.RefundedAmount
+		}
+		if amount <= 0 {
+			svc.l.Warnf(ctx, "payment %s: %v", p.ID.Hex(), ErrRefundExceedsPayment)
+			return nil, status.Error(codes.FailedPrecondition, ErrRefundExceedsPayment.Error())
+		}
+		if amount > p.Amount {
+			svc.l.Warnf(ctx, "refund of %.0f exceeds payment %s: %v", amount, p.ID.Hex(), ErrAmountMismatch)
+			return nil, status.Error(codes.InvalidArgument, ErrAmountMismatch.Error())
+		}
+
+		rf = models.PaymentRefund{
+			ID:        id,
+			Amount:    amount,
+			Reason:    req.Reason,
+			Status:    models.PaymentStatusPending,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	gw, err := svc.gwf.GetGateway(p.Provider)
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to get payment gateway: %v", err)
+		return nil, status.Error(codes.Internal, ErrInternal.Error())
+	}
+
+	rfr, ok := gw.(Refunder)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", p.Provider, ErrRefundNotSupported)
+		return nil, status.Errorf(codes.FailedPrecondition, "gateway %s: %v", p.Provider, ErrRefundNotSupported)
+	}
+
+	// The refund is reserved before the provider is called, so concurrent
+	// refunds cannot together exceed what was paid.
+	reserved, err := svc.repo.ReserveRefund(ctx, p.ID, rf)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrStatusConflict):
+			svc.l.Warnf(ctx, "payment %s left completed: %v", p.ID.Hex(), ErrPaymentNotCompleted)
+			return nil, status.Error(codes.FailedPrecondition, ErrPaymentNotCompleted.Error())
+		case errors.Is(err, repository.ErrAmountExceeded):
+			svc.l.Warnf(ctx, "refund of %.0f for payment %s: %v", rf.Amount, p.ID.Hex(), ErrRefundExceedsPayment)
+			return nil, status.Error(codes.FailedPrecondition, ErrRefundExceedsPayment.Error())
+		}
+		svc.l.Errorf(ctx, "failed to reserve refund %s: %v", rf.ID, err)
+		return nil, status.Error(codes.Internal, ErrInternal.Error())
+	}
+	// A concurrent attempt may have finished the refund meanwhile.
+	p = reserved
+	if rf, _ = findRefund(p, id); rf.Status == models.PaymentStatusCompleted {
+		return toRefundResponse(p, rf), nil
+	}
+
+	var gatewayRef string
+	if err := svc.health.Do(p.Provider, func() error {
+		var rErr error
+		gatewayRef, rErr = rfr.RefundPayment(ctx, p, rf)
+		return rErr
+	}); err != nil {
+		if rErr := svc.repo.ReleaseRefund(ctx, p.ID, rf.ID); rErr != nil {
+			svc.l.Errorf(ctx, "failed to release refund %s of payment %s: %v", rf.ID, p.ID.Hex(), rErr)
+		}
+		return nil, svc.gatewayError(ctx, "refund payment "+p.ID.Hex(), err)
+	}
+
+	// Partial refunds leave the payment completed; only the refund is recorded.
+	up, err := svc.repo.RecordRefund(ctx, p.ID, rf.ID, gatewayRef)
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to record refund %s of payment %s: %v", rf.ID, p.ID.Hex(), err)
+		return nil, status.Error(codes.Internal, ErrInternal.Error())
+	}
+	rf, _ = findRefund(up, id)
+
+	return toRefundResponse(up, rf), nil
+}
+
+// refundID derives the ID of a payment's refund from the key of the request
+// for it, short enough for providers to take as their merchant refund ID.
+func refundID(paymentID string, key string) string {
+	sum := sha256.Sum256([]byte(paymentID + ":" + key))
+	return hex.EncodeToString(sum[:10])
+}
+
+func findRefund(p models.Payment, id string) (models.PaymentRefund, bool) {
+	for _, rf := range p.Refunds {
+		if rf.ID == id {
+			return rf, true
+		}
+	}
+	return models.PaymentRefund{}, false
+}
+
+func (svc *implPaymentService) CapturePayment(ctx context.Context, req *payment.CapturePaymentRequest) (*emptypb.Empty, error) {
+	p, err := svc.findPayment(ctx, req.OrderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, err := svc.gwf.GetGateway(p.Provider)
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to get payment gateway: %v", err)
+		return nil, status.Error(codes.Internal, ErrInternal.Error())
+	}
+
+	cp, ok := gw.(Capturer)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", p.Provider, ErrCaptureNotSupported)
+		return nil, status.Errorf(codes.FailedPrecondition, "gateway %s: %v", p.Provider, ErrCaptureNotSupported)
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = p.Amount
+	}
+	if err := svc.health.Do(p.Provider, func() error {
+		return cp.CapturePayment(ctx, p, amount)
+	}); err != nil {
+		return nil, svc.gatewayError(ctx, "capture payment "+p.ID.Hex(), err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *implPaymentService) CreatePaymentToken(ctx context.Context, req *payment.CreatePaymentTokenRequest) (*payment.CreatePaymentTokenResponse, error) {
+	gw, err := svc.gwf.GetGateway(models.GatewayType(req.Provider))
+	if err != nil {
+		svc.l.Warnf(ctx, "failed to get payment gateway: %v", err)
+		return nil, status.Error(codes.InvalidArgument, ErrInvalidGateway.Error())
+	}
+
+	tk, ok := gw.(Tokenizer)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", req.Provider, ErrTokenizeNotSupported)
+		return nil, status.Errorf(codes.Unimplemented, "gateway %s: %v", req.Provider, ErrTokenizeNotSupported)
+	}
+
+	var res *payment.CreatePaymentTokenResponse
+	if err := svc.health.Do(models.GatewayType(req.Provider), func() error {
+		var tErr error
+		res, tErr = tk.CreatePaymentToken(ctx, req)
+		return tErr
+	}); err != nil {
+		return nil, svc.gatewayError(ctx, "create payment token", err)
+	}
+
+	return res, nil
+}
+
+func (svc *implPaymentService) ListProviders(ctx context.Context, _ *emptypb.Empty) (*payment.ListProvidersResponse, error) {
+	res := &payment.ListProvidersResponse{}
+	envs := svc.gwf.Environments()
+	for gt, caps := range svc.gwf.Capabilities() {
+		info := &payment.ProviderInfo{Name: string(gt), Environment: envs[gt]}
+		for _, c := range caps {
+			info.Capabilities = append(info.Capabilities, string(c))
+		}
+		res.Providers = append(res.Providers, info)
+	}
+
+	sort.Slice(res.Providers, func(i, j int) bool {
+		return res.Providers[i].Name < res.Providers[j].Name
+	})
+	return res, nil
+}
+
+func (svc *implPaymentService) ListBanks(ctx context.Context, req *payment.ListBanksRequest) (*payment.ListBanksResponse, error) {
+	gt := models.GatewayType(req.Provider)
+	gw, err := svc.gwf.GetGateway(gt)
+	if err != nil {
+		svc.l.Warnf(ctx, "failed to get payment gateway: %v", err)
+		return nil, status.Error(codes.InvalidArgument, ErrInvalidGateway.Error())
+	}
+
+	bl, ok := gw.(BankLister)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", req.Provider, ErrBankListNotSupported)
+		return nil, status.Errorf(codes.FailedPrecondition, "gateway %s: %v", req.Provider, ErrBankListNotSupported)
+	}
+
+	var banks []Bank
+	if err := svc.health.Do(gt, func() error {
+		var bErr error
+		banks, bErr = bl.ListBanks(ctx)
+		return bErr
+	}); err != nil {
+		return nil, svc.gatewayError(ctx, "list banks", err)
+	}
+
+	res := &payment.ListBanksResponse{}
+	for _, b := range banks {
+		if req.Amount > 0 && !b.Accepts(req.Amount) {
+			continue
+		}
+		res.Banks = append(res.Banks, toProtoBank(b))
+	}
+
+	sort.SliceStable(res.Banks, func(i, j int) bool {
+		return res.Banks[i].DisplayOrder < res.Banks[j].DisplayOrder
+	})
+	return res, nil
+}
+
+func (svc *implPaymentService) findPaymentByID(ctx context.Context, id string) (models.Payment, error) {
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		svc.l.Warnf(ctx, "invalid payment id %q: %v", id, ErrInvalidInput)
+		return models.Payment{}, status.Error(codes.InvalidArgument, ErrInvalidInput.Error())
+	}
+
+	p, err := svc.repo.FindByID(ctx, oID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			svc.l.Warnf(ctx, "payment %s: %v", id, ErrPaymentNotFound)
+			return models.Payment{}, status.Error(codes.NotFound, ErrPaymentNotFound.Error())
+		}
+		svc.l.Errorf(ctx, "failed to find payment: %v", err)
+		return models.Payment{}, status.Error(codes.Internal, ErrInternal.Error())
+	}
+
+	return p, nil
+}
@@ -0,0 +1,116 @@
+package banktransfer
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (svc *implPaymentService) ConfirmCashCollected(ctx context.Context, req *payment.ConfirmCashCollectedRequest) (*emptypb.Empty, error) {
+	p, err := svc.findCODPayment(ctx, req.OrderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// The delivery service retries on timeouts, so a repeated report for the
+	// same courier reference is acknowledged without starting a second
+	// workflow. A report whose signal was never queued queues it now.
+	if p.Status == models.PaymentStatusCompleted && p.GatewayReference == req.CourierReference {
+		if p.Metadata[metadataSignalled] == string(models.PaymentStatusCompleted) {
+			return &emptypb.Empty{}, nil
+		}
+		return svc.queueCashCollected(ctx, p, p.CollectedAmount)
+	}
+	if p.Status != models.PaymentStatusPending {
+		svc.l.Warnf(ctx, "cod payment %s is %s: %v", p.ID.Hex(), p.Status, ErrPaymentNotPending)
+		return nil, status.Error(codes.FailedPrecondition, ErrPaymentNotPending.Error())
+	}
+
+	if math.Abs(p.Amount-req.CollectedAmount) >= amountTolerance {
+		svc.l.Warnf(ctx, "cod payment %s: collected %.0f, expected %.0f: %v", p.ID.Hex(), req.CollectedAmount, p.Amount, ErrAmountMismatch)
+		return nil, status.Error(codes.FailedPrecondition, ErrAmountMismatch.Error())
+	}
+
+	up, err := svc.repo.UpdateStatus(ctx, p.ID, repository.UpdateStatusOptions{
+		Status:           models.PaymentStatusCompleted,
+		From:             []models.PaymentStatus{models.PaymentStatusPending},
+		GatewayReference: req.CourierReference,
+		CollectedAmount:  req.CollectedAmount,
+	})
+	if errors.Is(err, repository.ErrStatusConflict) {
+		svc.l.Warnf(ctx, "cod payment %s left pending concurrently: %v", p.ID.Hex(), ErrPaymentNotPending)
+		return nil, status.Error(codes.FailedPrecondition, ErrPaymentNotPending.Error())
+	}
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to complete cod payment: %v", err)
+		return nil, status.Error(codes.Internal, ErrInternal.Error())
+	}
+
+	return svc.queueCashCollected(ctx, up, req.CollectedAmount)
+}
+
+// queueCashCollected signals the completed payment through the callback
+// outbox, which keeps retrying if the workflow cannot be started now.
+func (svc *implPaymentService) queueCashCollected(ctx context.Context, p models.Payment, collected float64) (*emptypb.Empty, error) {
+	e, err := svc.enqueueCallback(ctx, PaymentSignal{
+		PaymentID:        p.ID.Hex(),
+		OrderCode:        p.OrderCode,
+		Provider:         string(p.Provider),
+		Amount:           collected,
+		GatewayReference: p.GatewayReference,
+		Status:           models.PaymentStatusCompleted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	svc.dispatchCallback(ctx, e.ID)
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *implPaymentService) ReportDeliveryReturned(ctx context.Context, req *payment.ReportDeliveryReturnedRequest) (*emptypb.Empty, error) {
+	p, err := svc.findCODPayment(ctx, req.OrderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Status != models.PaymentStatusPending && p.Status != models.PaymentStatusFailed {
+		svc.l.Warnf(ctx, "cod payment %s is %s: %v", p.ID.Hex(), p.Status, ErrPaymentNotPending)
+		return nil, status.Error(codes.FailedPrecondition, ErrPaymentNotPending.Error())
+	}
+
+	// Failing the payment like a declined callback signals the workflow and
+	// starts compensation, which releases the order's items. A repeated
+	// report is acknowledged once the failure has been signalled.
+	if err := svc.applyCallback(ctx, CallbackEvent{
+		OrderCode:        p.OrderCode,
+		Status:           models.PaymentStatusFailed,
+		GatewayReference: req.CourierReference,
+		Amount:           p.Amount,
+		Reason:           req.Reason,
+	}); err != nil && !errors.Is(err, ErrDuplicateCallback) {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (svc *implPaymentService) findCODPayment(ctx context.Context, orderCode string) (models.Payment, error) {
+	p, err := svc.findPayment(ctx, orderCode)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	if p.Method != models.PaymentMethodCOD {
+		svc.l.Warnf(ctx, "payment %s is not cash on delivery: %v", p.ID.Hex(), ErrInvalidGateway)
+		return models.Payment{}, status.Error(codes.FailedPrecondition, ErrInvalidGateway.Error())
+	}
+
+	return p, nil
+}
@@ -0,0 +1,44 @@
+package cod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+)
+
+const paymentIDPrefix = "COD_"
+
+// ProcessPayment has nothing to redirect to: the payment stays pending until
+// the delivery service reports the cash as collected or the parcel returned.
+func (g *CODGateway) ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest, ord *order.OrderData) (*payment.ProcessPaymentResponse, error) {
+	return &payment.ProcessPaymentResponse{
+		Payment: &payment.PaymentData{
+			Id:              paymentIDPrefix + req.OrderCode,
+			OrderCode:       req.OrderCode,
+			UserId:          req.UserId,
+			Amount:          req.Amount,
+			Provider:        string(models.GatewayTypeCOD),
+			ProviderDetails: req.ProviderDetails,
+			Metadata:        req.Metadata,
+		},
+	}, nil
+}
+
+func (g *CODGateway) PaymentMethod() models.PaymentMethod {
+	return models.PaymentMethodCOD
+}
+
+// ParseCallback rejects everything: cash collection is reported through
+// ConfirmCashCollected, not by a provider calling back.
+func (g *CODGateway) ParseCallback(ctx context.Context, r *http.Request) (bankTf.CallbackEvent, error) {
+	return bankTf.CallbackEvent{}, fmt.Errorf("%w: cod gateway does not accept callbacks", bankTf.ErrInvalidCallback)
+}
+
+func (g *CODGateway) AcknowledgeCallback(w http.ResponseWriter, err error) {
+	bankTf.WriteJSONAck(w, err)
+}
@@ -0,0 +1,11 @@
+package cod
+
+import (
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+)
+
+type CODGateway struct{}
+
+func New() bankTf.PaymentGateway {
+	return &CODGateway{}
+}
@@ -0,0 +1,90 @@
+package banktransfer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.temporal.io/api/serviceerror"
+)
+
+func (e *callbackEnv) addCODPayment(t *testing.T, orderCode string, amount float64) models.Payment {
+	t.Helper()
+
+	p, err := e.payments.Create(context.Background(), models.Payment{
+		OrderCode: orderCode,
+		Provider:  models.GatewayTypeCOD,
+		Method:    models.PaymentMethodCOD,
+		Status:    models.PaymentStatusPending,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func TestConfirmCashCollectedRequeuesAfterSignalFailure(t *testing.T) {
+	env := newCallbackEnv(t)
+	env.addCODPayment(t, "ORD-COD-1", 120000)
+	req := &payment.ConfirmCashCollectedRequest{OrderCode: "ORD-COD-1", CollectedAmount: 120000, CourierReference: "GHN-1"}
+
+	down := serviceerror.NewUnavailable("temporal unavailable")
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-COD-1", "", bankTf.SignalNamePaymentCompleted, mock.Anything).
+		Return(down).Once()
+
+	// The collection is recorded and queued even though Temporal is down.
+	_, err := env.svc.ConfirmCashCollected(context.Background(), req)
+	require.NoError(t, err)
+	p := env.payment(t, "ORD-COD-1")
+	require.Equal(t, models.PaymentStatusCompleted, p.Status)
+	require.Equal(t, 120000.0, p.CollectedAmount)
+	require.Empty(t, p.Metadata["workflow_signalled"])
+
+	// The courier's retry finds it unsignalled and keeps the queued entry.
+	_, err = env.svc.ConfirmCashCollected(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, env.outbox.all(), 1)
+
+	d, err := bankTf.NewCallbackDispatcher(env.svc)
+	require.NoError(t, err)
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-COD-1", "", bankTf.SignalNamePaymentCompleted,
+		mock.MatchedBy(func(sig bankTf.PaymentSignal) bool {
+			return sig.GatewayReference == "GHN-1" && sig.Amount == 120000
+		})).Return(nil).Once()
+	require.Equal(t, 1, dispatchDue(d))
+	require.Equal(t, string(models.PaymentStatusCompleted), env.payment(t, "ORD-COD-1").Metadata["workflow_signalled"])
+
+	// Once signalled, a repeated report changes nothing.
+	_, err = env.svc.ConfirmCashCollected(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, env.outbox.all(), 1)
+}
+
+func TestReportDeliveryReturnedStartsCompensation(t *testing.T) {
+	env := newCallbackEnv(t)
+	p := env.addCODPayment(t, "ORD-COD-2", 80000)
+
+	env.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+"ORD-COD-2", "", bankTf.SignalNamePaymentFailed, mock.Anything).
+		Return(serviceerror.NewNotFound("workflow not found")).Once()
+	var params bankTf.CompensationParams
+	env.temporal.On("ExecuteWorkflow", mock.Anything, mock.Anything, bankTf.WorkflowCompensatePayment, mock.Anything).
+		Run(func(args mock.Arguments) { params = args.Get(3).(bankTf.CompensationParams) }).
+		Return(workflowRun(bankTf.WorkflowCompensationPrefix+"ORD-COD-2"), nil).Once()
+
+	req := &payment.ReportDeliveryReturnedRequest{OrderCode: "ORD-COD-2", CourierReference: "GHN-2", Reason: "customer refused"}
+	_, err := env.svc.ReportDeliveryReturned(context.Background(), req)
+	require.NoError(t, err)
+
+	got := env.payment(t, "ORD-COD-2")
+	require.Equal(t, models.PaymentStatusFailed, got.Status)
+	require.Equal(t, string(models.PaymentStatusFailed), got.Metadata["workflow_signalled"])
+	require.Equal(t, p.ID.Hex(), params.PaymentID)
+	require.Equal(t, "ORD-COD-2", params.OrderCode)
+
+	// The courier's retry starts nothing new.
+	_, err = env.svc.ReportDeliveryReturned(context.Background(), req)
+	require.NoError(t, err)
+}
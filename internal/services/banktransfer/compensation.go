@@ -0,0 +1,274 @@
+package banktransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/metrics"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/product"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/grpc/metadata"
+)
+
+// Compensation steps, as reported in CompensationResult and metrics.
+const (
+	CompensationStepFindOrder        = "find_order"
+	CompensationStepFailOrder        = "fail_order"
+	CompensationStepReleaseInventory = "release_inventory"
+)
+
+// idempotencyKeyHeader carries the key of a compensation step to the order
+// and product services, so a retried call is applied once. RefundPayment
+// takes its callers' keys the same way.
+const idempotencyKeyHeader = "idempotency-key"
+
+// startCompensation starts the compensation of an order whose payment failed
+// for good. There is one per order: a repeated start is a no-op.
+func (svc *implPaymentService) startCompensation(ctx context.Context, sig PaymentSignal) error {
+	wfID := WorkflowCompensationPrefix + sig.OrderCode
+	we, err := svc.temporal.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:                                       wfID,
+		TaskQueue:                                svc.wfCfg.PaymentTaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+		TypedSearchAttributes:                    svc.searchAttributes(sig),
+	}, WorkflowCompensatePayment, CompensationParams{
+		OrderCode: sig.OrderCode,
+		PaymentID: sig.PaymentID,
+		Provider:  sig.Provider,
+		Amount:    sig.Amount,
+		Reason:    sig.Reason,
+	})
+	var started *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &started) {
+		svc.l.Infof(ctx, "Compensation %s already started", wfID)
+		return nil
+	}
+	if err != nil {
+		svc.l.Errorf(ctx, "Failed to start compensation for order %s: %v", sig.OrderCode, err)
+		return err
+	}
+
+	svc.l.Infof(ctx, "Compensation started. WorkflowID: %s, RunID: %s", we.GetID(), we.GetRunID())
+	return nil
+}
+
+// CompensatePaymentWorkflow undoes the order's reservation: the order goes
+// to PAYMENT_FAILED and its items are released. Orders no longer waiting
+// for payment are left alone. Steps still failing after their retries are
+// recorded on the payment and fail the workflow, so they show up for an
+// operator.
+func CompensatePaymentWorkflow(ctx workflow.Context, params CompensationParams) (CompensationResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    5 * time.Minute,
+			MaximumAttempts:    10,
+		},
+	})
+
+	res := CompensationResult{OrderCode: params.OrderCode, PaymentID: params.PaymentID}
+	state := PaymentState{
+		OrderCode: params.OrderCode,
+		PaymentID: params.PaymentID,
+		Provider:  params.Provider,
+		Amount:    params.Amount,
+		Status:    models.PaymentStatusFailed,
+		Step:      StepFindingOrder,
+	}
+	if err := trackPaymentState(ctx, &state); err != nil {
+		return res, err
+	}
+
+	var ord CompensationOrder
+	if err := workflow.ExecuteActivity(ctx, ActivityFindOrder, params.OrderCode).Get(ctx, &ord); err != nil {
+		state.failed(ctx, err)
+		res.Failed = append(res.Failed, CompensationStepFindOrder)
+		return res, finishCompensation(ctx, &state, res)
+	}
+
+	if ord.Status != order.OrderStatus_INVENTORY_RESERVED && ord.Status != order.OrderStatus_PAYMENT_PENDING {
+		res.Skipped = "order is " + ord.Status.String()
+		return res, finishCompensation(ctx, &state, res)
+	}
+
+	state.step(ctx, StepFailingOrder)
+	if err := workflow.ExecuteActivity(ctx, ActivityUpdateOrderStatus, OrderStatusParams{
+		OrderCode:      params.OrderCode,
+		Status:         order.OrderStatus_PAYMENT_FAILED,
+		IdempotencyKey: compensationKey(params.OrderCode, CompensationStepFailOrder),
+	}).Get(ctx, nil); err != nil {
+		state.failed(ctx, err)
+		res.Failed = append(res.Failed, CompensationStepFailOrder)
+	} else {
+		res.Completed = append(res.Completed, CompensationStepFailOrder)
+	}
+
+	// Released even when failing the order did not work: the payment is
+	// dead either way, and held stock costs sales.
+	if len(ord.Items) > 0 {
+		state.step(ctx, StepReleasingStock)
+		if err := workflow.ExecuteActivity(ctx, ActivityReleaseInventory, ReleaseInventoryParams{
+			OrderCode:      params.OrderCode,
+			Items:          ord.Items,
+			IdempotencyKey: compensationKey(params.OrderCode, CompensationStepReleaseInventory),
+		}).Get(ctx, nil); err != nil {
+			state.failed(ctx, err)
+			res.Failed = append(res.Failed, CompensationStepReleaseInventory)
+		} else {
+			res.Completed = append(res.Completed, CompensationStepReleaseInventory)
+		}
+	}
+
+	return res, finishCompensation(ctx, &state, res)
+}
+
+func finishCompensation(ctx workflow.Context, state *PaymentState, res CompensationResult) error {
+	state.step(ctx, StepRecordingOutcome)
+	if err := workflow.ExecuteActivity(ctx, ActivityRecordCompensation, res).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("failed to record compensation", "OrderCode", res.OrderCode, "Error", err)
+		state.failed(ctx, err)
+	}
+	state.step(ctx, StepDone)
+
+	if len(res.Failed) > 0 {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("compensation of order %s incomplete: %s", res.OrderCode, strings.Join(res.Failed, ", ")),
+			"CompensationIncomplete", nil, res)
+	}
+	return nil
+}
+
+func compensationKey(orderCode string, step string) string {
+	return "payment-compensation:" + orderCode + ":" + step
+}
+
+func withIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, idempotencyKeyHeader, key)
+}
+
+// FindOrder reads what compensation needs from the order service.
+func (a *PaymentActivities) FindOrder(ctx context.Context, orderCode string) (CompensationOrder, error) {
+	res, err := a.svc.orderSvc.FindOne(ctx, &order.FindOneRequest{Request: &order.FindOneRequest_Code{Code: orderCode}})
+	if err != nil {
+		a.svc.l.Errorf(ctx, "failed to find order %s: %v", orderCode, err)
+		return CompensationOrder{}, activityError(err)
+	}
+	if res == nil || res.Order == nil {
+		return CompensationOrder{}, temporal.NewNonRetryableApplicationError(ErrOrderNotFound.Error(), "NotFound", ErrOrderNotFound)
+	}
+
+	ord := CompensationOrder{Status: res.Order.Status}
+	for _, it := range res.Order.Items {
+		ord.Items = append(ord.Items, InventoryItem{ProductID: it.ProductId, Quantity: it.Quantity})
+	}
+	return ord, nil
+}
+
+func (a *PaymentActivities) ReleaseInventory(ctx context.Context, params ReleaseInventoryParams) error {
+	req := &product.ReleaseInventoryRequest{}
+	for _, it := range params.Items {
+		req.Items = append(req.Items, &product.ReleaseInventoryItem{ProductId: it.ProductID, Quantity: it.Quantity})
+	}
+
+	if _, err := a.productSvc.ReleaseInventory(withIdempotencyKey(ctx, params.IdempotencyKey), req); err != nil {
+		a.svc.l.Errorf(ctx, "failed to release inventory of order %s: %v", params.OrderCode, err)
+		return activityError(err)
+	}
+	return nil
+}
+
+// RecordCompensation stores the outcome on the payment and reports steps
+// that could not be compensated.
+func (a *PaymentActivities) RecordCompensation(ctx context.Context, res CompensationResult) error {
+	outcome := compensationOutcome(res)
+	metrics.Compensations.WithLabelValues(outcome).Inc()
+	for _, step := range res.Failed {
+		metrics.CompensationStepFailures.WithLabelValues(step).Inc()
+	}
+	if len(res.Failed) > 0 {
+		a.svc.l.Errorf(ctx, "compensation of order %s incomplete, failed steps: %s", res.OrderCode, strings.Join(res.Failed, ", "))
+	}
+
+	if res.PaymentID == "" {
+		return nil
+	}
+	p, err := a.svc.findPaymentByID(ctx, res.PaymentID)
+	if err != nil {
+		return activityError(err)
+	}
+
+	md := map[string]string{metadataCompensation: outcome}
+	if len(res.Failed) > 0 {
+		md[metadataCompensationFailed] = strings.Join(res.Failed, ",")
+	}
+	if res.Skipped != "" {
+		md[metadataCompensationSkipped] = res.Skipped
+	}
+	if _, err := a.svc.repo.UpdateStatus(ctx, p.ID, repository.UpdateStatusOptions{
+		Status:   p.Status,
+		Metadata: md,
+	}); err != nil {
+		a.svc.l.Errorf(ctx, "failed to record compensation of payment %s: %v", res.PaymentID, err)
+		return err
+	}
+	return nil
+}
+
+func compensationOutcome(res CompensationResult) string {
+	switch {
+	case len(res.Failed) > 0:
+		return "incomplete"
+	case res.Skipped != "":
+		return "skipped"
+	default:
+		return "completed"
+	}
+}
+
+// ExpirePayment fails a payment still pending when its poll expires, which
+// starts compensation like any other failure.
+func (a *PaymentActivities) ExpirePayment(ctx context.Context, params PollPaymentParams) (models.PaymentStatus, error) {
+	p, err := a.svc.findPaymentByID(ctx, params.PaymentID)
+	if err != nil {
+		return "", activityError(err)
+	}
+	if p.Status != models.PaymentStatusPending {
+		return p.Status, nil
+	}
+
+	latest, err := a.svc.findPayment(ctx, p.OrderCode)
+	if err != nil {
+		return "", activityError(err)
+	}
+	if latest.ID != p.ID {
+		return p.Status, nil
+	}
+
+	a.svc.l.Warnf(ctx, "payment %s expired while pending", p.ID.Hex())
+	if err := a.svc.applyCallback(ctx, CallbackEvent{
+		OrderCode:        p.OrderCode,
+		Status:           models.PaymentStatusFailed,
+		GatewayReference: p.GatewayReference,
+		Amount:           p.Amount,
+		Reason:           "payment expired",
+	}); err != nil && !errors.Is(err, ErrDuplicateCallback) {
+		return "", activityError(err)
+	}
+	return models.PaymentStatusFailed, nil
+}
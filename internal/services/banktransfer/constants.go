@@ -1,9 +1,65 @@
 package banktransfer
 
 const (
-	TaskQueueName              = "POST_PAYMENT_ORDER_TASK_QUEUE"
 	WorkflowName               = "ProcessPostPaymentOrder"
 	WorkflowPrePaymentPrefix   = "order_pre_payment_"
 	WorkflowPostPaymentPrefix  = "order_post_payment_"
 	SignalNamePaymentCompleted = "payment-completed"
+	SignalNamePaymentFailed    = "payment-failed"
+)
+
+// Workflows and activities run by the payment worker on its own task queue,
+// TEMPORAL_PAYMENT_TASK_QUEUE. Activity names are the PaymentActivities
+// method names.
+const (
+	WorkflowSyncPaymentStatus  = "SyncPaymentStatus"
+	WorkflowRefundPayment      = "RefundPayment"
+	WorkflowPollPaymentStatus  = "PollPaymentStatus"
+	WorkflowPaymentPollPrefix  = "payment_poll_"
+	WorkflowCompensatePayment  = "CompensatePayment"
+	WorkflowCompensationPrefix = "payment_compensation_"
+
+	ActivityPersistCallbackResult = "PersistCallbackResult"
+	ActivityCheckPaymentStatus    = "CheckPaymentStatus"
+	ActivityExpirePayment         = "ExpirePayment"
+	ActivityFindOrder             = "FindOrder"
+	ActivityReleaseInventory      = "ReleaseInventory"
+	ActivityRecordCompensation    = "RecordCompensation"
+	ActivityQueryGatewayStatus    = "QueryGatewayStatus"
+	ActivitySettlePaymentStatus   = "SettlePaymentStatus"
+	ActivityUpdateOrderStatus     = "UpdateOrderStatus"
+	ActivityIssueRefund           = "IssueRefund"
+
+	QueryPaymentState = "payment-state"
+)
+
+// Steps reported by PaymentState.
+const (
+	StepWaiting          = "waiting"
+	StepCheckingStatus   = "checking_status"
+	StepExpiring         = "expiring"
+	StepRecordingResult  = "recording_result"
+	StepRefunding        = "refunding"
+	StepFindingOrder     = "finding_order"
+	StepFailingOrder     = "failing_order"
+	StepReleasingStock   = "releasing_inventory"
+	StepRecordingOutcome = "recording_compensation"
+	StepDone             = "done"
+)
+
+const (
+	DefaultCurrency = "VND"
+
+	// Amounts are in VND, so anything below one dong is rounding noise.
+	amountTolerance = 0.5
+	// Maximum edit distance between a memo token and an order code for the
+	// pair to be considered a fuzzy match.
+	fuzzyReferenceDistance = 1
+
+	metadataFailureReason = "failure_reason"
+	metadataSignalled     = "workflow_signalled"
+
+	metadataCompensation        = "compensation"
+	metadataCompensationFailed  = "compensation_failed_steps"
+	metadataCompensationSkipped = "compensation_skipped"
 )
@@ -0,0 +1,53 @@
+package banktransfer
+
+import (
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+)
+
+func toProtoStatus(s models.PaymentStatus) payment.PaymentStatus {
+	switch s {
+	case models.PaymentStatusPending:
+		return payment.PaymentStatus_PAYMENT_STATUS_PENDING
+	case models.PaymentStatusCompleted:
+		return payment.PaymentStatus_PAYMENT_STATUS_COMPLETED
+	case models.PaymentStatusFailed:
+		return payment.PaymentStatus_PAYMENT_STATUS_FAILED
+	case models.PaymentStatusCancelled:
+		return payment.PaymentStatus_PAYMENT_STATUS_CANCELLED
+	case models.PaymentStatusRefunded:
+		return payment.PaymentStatus_PAYMENT_STATUS_REFUNDED
+	default:
+		return payment.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+	}
+}
+
+func toPaymentData(p models.Payment) *payment.PaymentData {
+	return &payment.PaymentData{
+		Id:        p.ID.Hex(),
+		OrderCode: p.OrderCode,
+		UserId:    p.UserID,
+		Amount:    p.Amount,
+		Provider:  string(p.Provider),
+		Metadata:  p.Metadata,
+		Status:    toProtoStatus(p.Status),
+	}
+}
+
+func toProtoBank(b Bank) *payment.Bank {
+	return &payment.Bank{
+		Code:         b.Code,
+		Name:         b.Name,
+		Channel:      b.Channel,
+		MinAmount:    b.MinAmount,
+		MaxAmount:    b.MaxAmount,
+		DisplayOrder: int32(b.DisplayOrder),
+	}
+}
+
+func toRefundResponse(p models.Payment, rf models.PaymentRefund) *payment.RefundPaymentResponse {
+	return &payment.RefundPaymentResponse{
+		RefundId: rf.GatewayReference,
+		Status:   toProtoStatus(p.Status),
+	}
+}
@@ -1,6 +1,9 @@
 package banktransfer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var WarnError = []error{
 	ErrInvalidInput,
@@ -8,6 +11,24 @@ var WarnError = []error{
 	ErrOrderNotFound,
 	ErrOrderNotCompleted,
 	ErrOrderNotPending,
+	ErrInvalidStatement,
+	ErrPaymentNotFound,
+	ErrPaymentNotPending,
+	ErrAmountMismatch,
+	ErrPaymentNotCompleted,
+	ErrCancelNotSupported,
+	ErrStatusQueryNotSupported,
+	ErrRefundNotSupported,
+	ErrRefundExceedsPayment,
+	ErrCaptureNotSupported,
+	ErrTokenizeNotSupported,
+	ErrBankListNotSupported,
+	ErrRedirectNotSupported,
+	ErrModeNotSupported,
+	ErrNoEligibleGateway,
+	ErrInvalidCallback,
+	ErrOutboxEntryNotFound,
+	ErrOutboxEntryNotDead,
 }
 
 var (
@@ -18,6 +39,31 @@ var (
 	ErrOrderNotPending   = errors.New("order is not pending")
 	ErrOrderNotCompleted = errors.New("order is not completed")
 	ErrInvalidGateway    = errors.New("invalid gateway")
+	ErrInvalidStatement  = errors.New("invalid bank statement")
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrPaymentNotPending = errors.New("payment is not pending")
+	ErrAmountMismatch    = errors.New("amount does not match payment")
+	ErrInvalidCallback   = errors.New("invalid callback")
+	ErrDuplicateCallback = errors.New("callback already processed")
+
+	ErrOutboxEntryNotFound = errors.New("callback outbox entry not found")
+	ErrOutboxEntryNotDead  = errors.New("callback outbox entry is not dead-lettered")
+
+	ErrPaymentNotCompleted     = errors.New("payment is not completed")
+	ErrRefundExceedsPayment    = errors.New("refunds exceed the amount paid")
+	ErrCancelNotSupported      = errors.New("gateway does not support cancelling payments")
+	ErrStatusQueryNotSupported = errors.New("gateway does not support querying payment status")
+	ErrRefundNotSupported      = errors.New("gateway does not support refunds")
+	ErrCaptureNotSupported     = errors.New("gateway does not support capturing payments")
+	ErrTokenizeNotSupported    = errors.New("gateway does not support payment tokens")
+	ErrBankListNotSupported    = errors.New("gateway does not support listing banks")
+	ErrRedirectNotSupported    = errors.New("gateway does not support verified redirects")
+	ErrModeNotSupported        = errors.New("gateway does not support the payment mode")
+
+	ErrNoEligibleGateway  = errors.New("no eligible gateway for payment")
+	ErrGatewayUnavailable = errors.New("gateway unavailable")
+	ErrCircuitOpen        = fmt.Errorf("%w: circuit breaker open", ErrGatewayUnavailable)
+	ErrInvalidSignature   = fmt.Errorf("%w: invalid mac", ErrInvalidCallback)
 )
 
 func IsWarnError(err error) bool {
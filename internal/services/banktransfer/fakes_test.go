@@ -0,0 +1,476 @@
+package banktransfer_test
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	pkgLog "github.com/vogiaan1904/payment-svc/pkg/log"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func testLogger() pkgLog.Logger {
+	return pkgLog.InitializeZapLogger(pkgLog.ZapConfig{
+		Level:    "error",
+		Encoding: "development",
+		Mode:     "console",
+	})
+}
+
+// memPayments is an in-memory PaymentRepository.
+type memPayments struct {
+	mu       sync.Mutex
+	payments []models.Payment
+}
+
+func (m *memPayments) Create(ctx context.Context, p models.Payment) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p.ID = primitive.NewObjectID()
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	m.payments = append(m.payments, p)
+	return p, nil
+}
+
+func (m *memPayments) FindByID(ctx context.Context, id primitive.ObjectID) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.payments {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return models.Payment{}, repository.ErrNotFound
+}
+
+func (m *memPayments) FindByOrderCode(ctx context.Context, orderCode string) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.payments) - 1; i >= 0; i-- {
+		if m.payments[i].OrderCode == orderCode {
+			return m.payments[i], nil
+		}
+	}
+	return models.Payment{}, repository.ErrNotFound
+}
+
+func (m *memPayments) FindByGatewayReference(ctx context.Context, ref string) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.payments {
+		if p.GatewayReference == ref {
+			return p, nil
+		}
+	}
+	return models.Payment{}, repository.ErrNotFound
+}
+
+func (m *memPayments) List(ctx context.Context, opts repository.ListPaymentsOptions) ([]models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var ps []models.Payment
+	for _, p := range m.payments {
+		if (opts.Status == "" || p.Status == opts.Status) && (opts.Method == "" || p.Method == opts.Method) {
+			ps = append(ps, p)
+		}
+	}
+	return ps, nil
+}
+
+func (m *memPayments) UpdateStatus(ctx context.Context, id primitive.ObjectID, opts repository.UpdateStatusOptions) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.payments {
+		p := &m.payments[i]
+		if p.ID != id {
+			continue
+		}
+		if len(opts.From) > 0 && !hasStatus(opts.From, p.Status) {
+			return models.Payment{}, repository.ErrStatusConflict
+		}
+
+		p.Status = opts.Status
+		p.UpdatedAt = time.Now()
+		if opts.GatewayReference != "" {
+			p.GatewayReference = opts.GatewayReference
+		}
+		if opts.CollectedAmount > 0 {
+			p.CollectedAmount = opts.CollectedAmount
+		}
+		if len(opts.Metadata) > 0 && p.Metadata == nil {
+			p.Metadata = make(map[string]string)
+		}
+		for k, v := range opts.Metadata {
+			p.Metadata[k] = v
+		}
+		return *p, nil
+	}
+	return models.Payment{}, repository.ErrNotFound
+}
+
+func (m *memPayments) ReserveRefund(ctx context.Context, id primitive.ObjectID, rf models.PaymentRefund) (models.Payment, error) {
+	return m.update(id, func(p *models.Payment) error {
+		if slices.ContainsFunc(p.Refunds, func(r models.PaymentRefund) bool { return r.ID == rf.ID }) {
+			return nil
+		}
+		if p.Status != models.PaymentStatusCompleted {
+			return repository.ErrStatusConflict
+		}
+		if p.RefundedAmount+rf.Amount > p.Amount {
+			return repository.ErrAmountExceeded
+		}
+		p.RefundedAmount += rf.Amount
+		p.Refunds = append(slices.Clone(p.Refunds), rf)
+		return nil
+	})
+}
+
+func (m *memPayments) ReleaseRefund(ctx context.Context, id primitive.ObjectID, refundID string) error {
+	_, err := m.update(id, func(p *models.Payment) error {
+		i := slices.IndexFunc(p.Refunds, func(r models.PaymentRefund) bool { return r.ID == refundID })
+		if i < 0 || p.Refunds[i].Status != models.PaymentStatusPending {
+			return nil
+		}
+		p.RefundedAmount -= p.Refunds[i].Amount
+		p.Refunds = slices.Delete(slices.Clone(p.Refunds), i, i+1)
+		if p.Status == models.PaymentStatusRefunded {
+			p.Status = models.PaymentStatusCompleted
+		}
+		return nil
+	})
+	return err
+}
+
+func (m *memPayments) RecordRefund(ctx context.Context, id primitive.ObjectID, refundID string, gatewayRef string) (models.Payment, error) {
+	return m.update(id, func(p *models.Payment) error {
+		i := slices.IndexFunc(p.Refunds, func(r models.PaymentRefund) bool { return r.ID == refundID })
+		if i < 0 {
+			return repository.ErrNotFound
+		}
+		p.Refunds = slices.Clone(p.Refunds)
+		p.Refunds[i].Status = models.PaymentStatusCompleted
+		p.Refunds[i].GatewayReference = gatewayRef
+		if p.RefundedAmount >= p.Amount {
+			p.Status = models.PaymentStatusRefunded
+		}
+		return nil
+	})
+}
+
+func (m *memPayments) update(id primitive.ObjectID, fn func(p *models.Payment) error) (models.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.payments {
+		p := &m.payments[i]
+		if p.ID != id {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return models.Payment{}, err
+		}
+		p.UpdatedAt = time.Now()
+		return *p, nil
+	}
+	return models.Payment{}, repository.ErrNotFound
+}
+
+func hasStatus(sts []models.PaymentStatus, st models.PaymentStatus) bool {
+	for _, s := range sts {
+		if s == st {
+			return true
+		}
+	}
+	return false
+}
+
+// memReviews is an in-memory StatementReviewRepository.
+type memReviews struct {
+	mu      sync.Mutex
+	reviews []models.StatementReview
+}
+
+func (m *memReviews) Upsert(ctx context.Context, r models.StatementReview) (models.StatementReview, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, x := range m.reviews {
+		if x.TransactionID == r.TransactionID {
+			r.ID = x.ID
+			m.reviews[i] = r
+			return r, nil
+		}
+	}
+	r.ID = primitive.NewObjectID()
+	m.reviews = append(m.reviews, r)
+	return r, nil
+}
+
+func (m *memReviews) all() []models.StatementReview {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]models.StatementReview(nil), m.reviews...)
+}
+
+// memOutbox is an in-memory CallbackOutboxRepository.
+type memOutbox struct {
+	mu      sync.Mutex
+	entries []*models.CallbackOutboxEntry
+}
+
+func (m *memOutbox) Enqueue(ctx context.Context, e models.CallbackOutboxEntry) (models.CallbackOutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, x := range m.entries {
+		if x.Key == e.Key {
+			return *x, nil
+		}
+	}
+
+	now := time.Now()
+	e.ID = primitive.NewObjectID()
+	e.Status = models.OutboxStatusPending
+	e.Attempts = 0
+	e.NextAttemptAt = now
+	e.CreatedAt = now
+	e.UpdatedAt = now
+	m.entries = append(m.entries, &e)
+	return e, nil
+}
+
+func (m *memOutbox) Claim(ctx context.Context, id primitive.ObjectID, until time.Time) (models.CallbackOutboxEntry, error) {
+	return m.claim(func(e *models.CallbackOutboxEntry) bool { return e.ID == id }, until)
+}
+
+func (m *memOutbox) ClaimNext(ctx context.Context, until time.Time) (models.CallbackOutboxEntry, error) {
+	return m.claim(func(*models.CallbackOutboxEntry) bool { return true }, until)
+}
+
+func (m *memOutbox) claim(match func(*models.CallbackOutboxEntry) bool, until time.Time) (models.CallbackOutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, e := range m.entries {
+		if match(e) && e.Status == models.OutboxStatusPending && !e.NextAttemptAt.After(now) {
+			e.NextAttemptAt = until
+			e.Attempts++
+			e.UpdatedAt = now
+			return *e, nil
+		}
+	}
+	return models.CallbackOutboxEntry{}, repository.ErrNotFound
+}
+
+func (m *memOutbox) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	return m.update(id, func(e *models.CallbackOutboxEntry) {
+		now := time.Now()
+		e.Status = models.OutboxStatusDispatched
+		e.DispatchedAt = &now
+		e.LastError = ""
+	})
+}
+
+func (m *memOutbox) MarkFailed(ctx context.Context, id primitive.ObjectID, opts repository.MarkFailedOptions) error {
+	return m.update(id, func(e *models.CallbackOutboxEntry) {
+		e.LastError = opts.Error
+		e.NextAttemptAt = opts.NextAttemptAt
+		if opts.DeadLetter {
+			e.Status = models.OutboxStatusDeadLetter
+		}
+	})
+}
+
+func (m *memOutbox) List(ctx context.Context, opts repository.ListOutboxOptions) ([]models.CallbackOutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var es []models.CallbackOutboxEntry
+	for _, e := range m.entries {
+		if opts.Status == "" || e.Status == opts.Status {
+			es = append(es, *e)
+		}
+	}
+	return es, nil
+}
+
+func (m *memOutbox) Redrive(ctx context.Context, id primitive.ObjectID) (models.CallbackOutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.entries {
+		if e.ID != id {
+			continue
+		}
+		if e.Status != models.OutboxStatusDeadLetter {
+			return models.CallbackOutboxEntry{}, repository.ErrStatusConflict
+		}
+		e.Status = models.OutboxStatusPending
+		e.Attempts = 0
+		e.NextAttemptAt = time.Now()
+		return *e, nil
+	}
+	return models.CallbackOutboxEntry{}, repository.ErrNotFound
+}
+
+func (m *memOutbox) update(id primitive.ObjectID, fn func(*models.CallbackOutboxEntry)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.entries {
+		if e.ID == id {
+			fn(e)
+			e.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (m *memOutbox) all() []models.CallbackOutboxEntry {
+	es, _ := m.List(context.Background(), repository.ListOutboxOptions{})
+	return es
+}
+
+// fakeOrderService stands in for the order service, and fakeProductService
+// for the product service. Status updates and inventory releases are
+// recorded with their idempotency keys.
+type fakeOrderService struct {
+	order.UnimplementedOrderServiceServer
+
+	mu      sync.Mutex
+	orders  map[string]*order.OrderData
+	updates []statusUpdate
+}
+
+type fakeProductService struct {
+	product.UnimplementedProductServiceServer
+
+	mu       sync.Mutex
+	releases []inventoryRelease
+}
+
+type statusUpdate struct {
+	OrderCode      string
+	Status         order.OrderStatus
+	IdempotencyKey string
+}
+
+type inventoryRelease struct {
+	Items          []*product.ReleaseInventoryItem
+	IdempotencyKey string
+}
+
+func (f *fakeOrderService) addOrder(o *order.OrderData) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.orders[o.Code] = o
+}
+
+func (f *fakeOrderService) FindOne(ctx context.Context, req *order.FindOneRequest) (*order.FindOneResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, ok := f.orders[req.GetCode()]
+	if !ok {
+		return &order.FindOneResponse{}, nil
+	}
+	return &order.FindOneResponse{Order: o}, nil
+}
+
+func (f *fakeOrderService) UpdateStatus(ctx context.Context, req *order.UpdateStatusRequest) (*emptypb.Empty, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if o, ok := f.orders[req.GetCode()]; ok {
+		o.Status = req.Status
+	}
+	f.updates = append(f.updates, statusUpdate{
+		OrderCode:      req.GetCode(),
+		Status:         req.Status,
+		IdempotencyKey: idempotencyKey(ctx),
+	})
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeProductService) ReleaseInventory(ctx context.Context, req *product.ReleaseInventoryRequest) (*emptypb.Empty, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.releases = append(f.releases, inventoryRelease{Items: req.Items, IdempotencyKey: idempotencyKey(ctx)})
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeOrderService) statusUpdates() []statusUpdate {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]statusUpdate(nil), f.updates...)
+}
+
+func (f *fakeProductService) inventoryReleases() []inventoryRelease {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]inventoryRelease(nil), f.releases...)
+}
+
+func idempotencyKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("idempotency-key"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// startOrderService serves the order and product fakes over bufconn and
+// returns clients for them, closed when the test ends.
+func startOrderService(t *testing.T) (*fakeOrderService, *fakeProductService, order.OrderServiceClient, product.ProductServiceClient) {
+	t.Helper()
+
+	orders := &fakeOrderService{orders: make(map[string]*order.OrderData)}
+	products := &fakeProductService{}
+	lis := bufconn.Listen(1 << 20)
+	sv := grpc.NewServer()
+	order.RegisterOrderServiceServer(sv, orders)
+	product.RegisterProductServiceServer(sv, products)
+	go sv.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial fake order service: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		sv.Stop()
+	})
+	return orders, products, order.NewOrderServiceClient(conn), product.NewProductServiceClient(conn)
+}
@@ -29,3 +29,40 @@ func (f *GatewayFactory) GetGateway(gatewayType models.GatewayType) (PaymentGate
 	}
 	return gateway, nil
 }
+
+// Capabilities reports the optional capabilities of every registered gateway.
+func (f *GatewayFactory) Capabilities() map[models.GatewayType][]Capability {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	caps := make(map[models.GatewayType][]Capability, len(f.gateways))
+	for gt, gw := range f.gateways {
+		caps[gt] = CapabilitiesOf(gw)
+	}
+	return caps
+}
+
+// Environments reports the provider environment of every registered gateway,
+// empty for gateways without one.
+func (f *GatewayFactory) Environments() map[models.GatewayType]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	envs := make(map[models.GatewayType]string, len(f.gateways))
+	for gt, gw := range f.gateways {
+		envs[gt] = EnvironmentOf(gw)
+	}
+	return envs
+}
+
+// Routes reports the paths every registered gateway gave its provider.
+func (f *GatewayFactory) Routes() map[models.GatewayType]Routes {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	routes := make(map[models.GatewayType]Routes, len(f.gateways))
+	for gt, gw := range f.gateways {
+		routes[gt] = RoutesOf(gw)
+	}
+	return routes
+}
@@ -0,0 +1,108 @@
+package banktransfer
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/circuitbreaker"
+	"github.com/vogiaan1904/payment-svc/internal/metrics"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+)
+
+// GatewayHealth keeps one circuit breaker per gateway so a degraded provider
+// fails fast instead of tying up every request until its HTTP call times out.
+type GatewayHealth struct {
+	settings circuitbreaker.Settings
+
+	mu       sync.Mutex
+	breakers map[models.GatewayType]*circuitbreaker.Breaker
+}
+
+// NewGatewayHealth counts only errors that mean the provider could not be
+// reached; a declined payment is a healthy response.
+func NewGatewayHealth(cfg config.CircuitBreakerConfig) *GatewayHealth {
+	return &GatewayHealth{
+		settings: circuitbreaker.Settings{
+			WindowSize:           cfg.WindowSize,
+			MinRequests:          cfg.MinRequests,
+			FailureRateThreshold: cfg.FailureRate,
+			SlowCallThreshold:    cfg.SlowCall,
+			OpenTimeout:          cfg.OpenTimeout,
+			HalfOpenMaxCalls:     cfg.HalfOpenProbes,
+			IsFailure:            IsRetryable,
+		},
+		breakers: make(map[models.GatewayType]*circuitbreaker.Breaker),
+	}
+}
+
+// Do runs a call to the gateway through its breaker. While the breaker is
+// open it returns ErrCircuitOpen without calling fn.
+func (h *GatewayHealth) Do(gatewayType models.GatewayType, fn func() error) error {
+	if h == nil {
+		return fn()
+	}
+
+	b := h.breaker(gatewayType)
+	gw := string(gatewayType)
+
+	start := time.Now()
+	err := b.Execute(fn)
+	switch {
+	case errors.Is(err, circuitbreaker.ErrOpen):
+		metrics.GatewayCalls.WithLabelValues(gw, "rejected").Inc()
+		return ErrCircuitOpen
+	case IsRetryable(err):
+		metrics.GatewayCalls.WithLabelValues(gw, "failure").Inc()
+	default:
+		metrics.GatewayCalls.WithLabelValues(gw, "success").Inc()
+	}
+	metrics.GatewayCallDuration.WithLabelValues(gw).Observe(time.Since(start).Seconds())
+	metrics.GatewayHealthScore.WithLabelValues(gw).Set(1 - b.Snapshot().FailureRate)
+
+	return err
+}
+
+func (h *GatewayHealth) Healthy(gatewayType models.GatewayType) bool {
+	if h == nil {
+		return true
+	}
+	return h.breaker(gatewayType).State() != circuitbreaker.StateOpen
+}
+
+func (h *GatewayHealth) Snapshot() map[models.GatewayType]circuitbreaker.Snapshot {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make(map[models.GatewayType]circuitbreaker.Snapshot, len(h.breakers))
+	for gt, b := range h.breakers {
+		out[gt] = b.Snapshot()
+	}
+	return out
+}
+
+func (h *GatewayHealth) breaker(gatewayType models.GatewayType) *circuitbreaker.Breaker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if b, ok := h.breakers[gatewayType]; ok {
+		return b
+	}
+
+	s := h.settings
+	gw := string(gatewayType)
+	s.OnStateChange = func(_, to circuitbreaker.State) {
+		metrics.GatewayCircuitState.WithLabelValues(gw).Set(float64(to))
+	}
+
+	b := circuitbreaker.New(s)
+	h.breakers[gatewayType] = b
+	metrics.GatewayCircuitState.WithLabelValues(gw).Set(float64(circuitbreaker.StateClosed))
+	metrics.GatewayHealthScore.WithLabelValues(gw).Set(1)
+	return b
+}
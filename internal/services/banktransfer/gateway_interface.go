@@ -2,13 +2,148 @@ package banktransfer
 
 import (
 	"context"
+	"net/http"
 
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
 	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// PaymentGateway is what every provider must support. Everything else is an
+// optional capability below that a gateway implements only when the provider
+// really offers it. ProcessPayment gets the order the payment is for, already
+// checked to be pending.
 type PaymentGateway interface {
-	ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest) (*payment.ProcessPaymentResponse, error)
-	HandleCallback(ctx context.Context, data interface{}) (string, error)
+	ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest, ord *order.OrderData) (*payment.ProcessPaymentResponse, error)
+	// ParseCallback reads and verifies a provider callback. The gateway owns
+	// the whole request, so body, headers and query are all fair game.
+	// Malformed or unverifiable callbacks wrap ErrInvalidCallback.
+	ParseCallback(ctx context.Context, r *http.Request) (CallbackEvent, error)
+	// AcknowledgeCallback writes the response the provider expects. err is
+	// the parse or processing error, nil once the callback has been handled.
+	AcknowledgeCallback(w http.ResponseWriter, err error)
+}
+
+// MethodReporter is implemented by gateways that settle through something
+// other than a bank transfer.
+type MethodReporter interface {
+	PaymentMethod() models.PaymentMethod
+}
+
+func MethodOf(gw PaymentGateway) models.PaymentMethod {
+	if mr, ok := gw.(MethodReporter); ok {
+		return mr.PaymentMethod()
+	}
+	return models.PaymentMethodBankTransfer
+}
+
+// EnvironmentReporter is implemented by gateways whose provider offers a
+// sandbox next to production.
+type EnvironmentReporter interface {
+	Environment() string
+}
+
+func EnvironmentOf(gw PaymentGateway) string {
+	if er, ok := gw.(EnvironmentReporter); ok {
+		return er.Environment()
+	}
+	return ""
+}
+
+// RouteReporter is implemented by gateways that tell their provider where
+// to call back and where to send customers back to. The HTTP server serves
+// those paths, so an instance can use others than /callbacks/{provider} and
+// /returns/{provider}.
+type RouteReporter interface {
+	Routes() Routes
+}
+
+// Routes are the paths a gateway handed its provider; empty ones are not
+// used.
+type Routes struct {
+	Callback string
+	Return   string
+}
+
+func RoutesOf(gw PaymentGateway) Routes {
+	if rr, ok := gw.(RouteReporter); ok {
+		return rr.Routes()
+	}
+	return Routes{}
+}
+
+type Canceller interface {
 	CancelPayment(ctx context.Context, req *payment.CancelPaymentRequest) (*emptypb.Empty, error)
 }
+
+type StatusQuerier interface {
+	QueryPaymentStatus(ctx context.Context, p models.Payment) (models.PaymentStatus, error)
+}
+
+// Refunder returns the provider's refund ID. rf.ID is the same every time a
+// refund is retried, so providers that take a merchant refund ID refund it
+// once. rf.Amount is never zero; the service resolves full refunds before
+// calling.
+type Refunder interface {
+	RefundPayment(ctx context.Context, p models.Payment, rf models.PaymentRefund) (string, error)
+}
+
+type Capturer interface {
+	CapturePayment(ctx context.Context, p models.Payment, amount float64) error
+}
+
+type Tokenizer interface {
+	CreatePaymentToken(ctx context.Context, req *payment.CreatePaymentTokenRequest) (*payment.CreatePaymentTokenResponse, error)
+}
+
+// BankLister lists the banks and channels a customer can pick from before
+// paying. Bank.Code is what ProcessPayment accepts as provider_details.
+type BankLister interface {
+	ListBanks(ctx context.Context) ([]Bank, error)
+}
+
+// RedirectVerifier is implemented by gateways whose provider signs the query
+// string it appends when redirecting the customer to the return URL.
+// Unverifiable redirects wrap ErrInvalidCallback.
+type RedirectVerifier interface {
+	ParseRedirect(ctx context.Context, r *http.Request) (RedirectEvent, error)
+}
+
+type Capability string
+
+const (
+	CapabilityCancel      Capability = "cancel"
+	CapabilityStatusQuery Capability = "status_query"
+	CapabilityRefund      Capability = "refund"
+	CapabilityCapture     Capability = "capture"
+	CapabilityTokenize    Capability = "tokenize"
+	CapabilityBankList    Capability = "bank_list"
+	CapabilityRedirect    Capability = "verified_redirect"
+)
+
+func CapabilitiesOf(gw PaymentGateway) []Capability {
+	var caps []Capability
+	if _, ok := gw.(Canceller); ok {
+		caps = append(caps, CapabilityCancel)
+	}
+	if _, ok := gw.(StatusQuerier); ok {
+		caps = append(caps, CapabilityStatusQuery)
+	}
+	if _, ok := gw.(Refunder); ok {
+		caps = append(caps, CapabilityRefund)
+	}
+	if _, ok := gw.(Capturer); ok {
+		caps = append(caps, CapabilityCapture)
+	}
+	if _, ok := gw.(Tokenizer); ok {
+		caps = append(caps, CapabilityTokenize)
+	}
+	if _, ok := gw.(BankLister); ok {
+		caps = append(caps, CapabilityBankList)
+	}
+	if _, ok := gw.(RedirectVerifier); ok {
+		caps = append(caps, CapabilityRedirect)
+	}
+	return caps
+}
@@ -0,0 +1,153 @@
+package gateways
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	codGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/cod"
+	mockGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/mock"
+	zpGW "github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
+	"github.com/vogiaan1904/payment-svc/pkg/log"
+)
+
+// defaultZalopayKey and defaultZalopayAppID are the envDefaults of
+// config.ZalopayConfig.
+const (
+	defaultZalopayKey   = "1234567890"
+	defaultZalopayAppID = 1234567890
+)
+
+const (
+	endpointCreate   = "create"
+	endpointQuery    = "query"
+	endpointRefund   = "refund"
+	endpointBanks    = "banks"
+	endpointHost     = "host"
+	endpointCallback = "callback"
+	endpointReturn   = "return"
+)
+
+func newZalopay(def config.GatewayDefinition, cfg *config.Config, l log.Logger) (bankTf.PaymentGateway, error) {
+	creds := cfg.PayGateway.Zalopay
+	if def.Credentials != "" {
+		if err := config.LoadCredentials(def.Credentials, &creds); err != nil {
+			return nil, err
+		}
+	}
+	sets, err := zalopayKeySets(creds)
+	if err != nil {
+		return nil, err
+	}
+	key1, key2, err := zalopayKeyrings(sets)
+	if err != nil {
+		return nil, err
+	}
+
+	env := environment(def)
+	if cfg.App.IsProduction() && env != config.EnvProduction {
+		return nil, ErrSandboxInProd
+	}
+	// Whatever APP_ENV says, ZaloPay's production API must not be called
+	// with the default or a sandbox app.
+	if env == config.EnvProduction {
+		if creds.AppID == defaultZalopayAppID {
+			return nil, fmt.Errorf("%w: app_id %d", ErrSandboxKeys, creds.AppID)
+		}
+		for _, s := range sets {
+			if s.Key1 == defaultZalopayKey || s.Key2 == defaultZalopayKey || zpGW.IsSandboxKey(s.Key1) || zpGW.IsSandboxKey(s.Key2) {
+				return nil, fmt.Errorf("%w: version %s", ErrSandboxKeys, s.Version)
+			}
+		}
+	}
+
+	gw := zpGW.New(creds.AppID, key1, key2, creds.Host)
+	gw.HTTP = httpClient(def, cfg, l)
+	if err := gw.SetEnvironment(env); err != nil {
+		return nil, err
+	}
+	if creds.BankListTTL > 0 {
+		gw.BankListTTL = creds.BankListTTL
+	}
+	if len(creds.Descriptions) > 0 {
+		gw.DescriptionTemplates = creds.Descriptions
+	}
+	if creds.DefaultLocale != "" {
+		gw.DefaultLocale = creds.DefaultLocale
+	}
+	setEndpoint(&gw.CreateZalopayPaymentLinkURL, def, endpointCreate)
+	setEndpoint(&gw.QueryURL, def, endpointQuery)
+	setEndpoint(&gw.RefundURL, def, endpointRefund)
+	setEndpoint(&gw.BankListURL, def, endpointBanks)
+	setEndpoint(&gw.Host, def, endpointHost)
+	gw.CallbackPath = callbackPath(def)
+	gw.ReturnPath = returnPath(def)
+
+	return gw, nil
+}
+
+func newCOD(def config.GatewayDefinition, cfg *config.Config, l log.Logger) (bankTf.PaymentGateway, error) {
+	return codGW.New(), nil
+}
+
+func newMock(def config.GatewayDefinition, cfg *config.Config, l log.Logger) (bankTf.PaymentGateway, error) {
+	creds := cfg.PayGateway.Mock
+	if def.Credentials != "" {
+		if err := config.LoadCredentials(def.Credentials, &creds); err != nil {
+			return nil, err
+		}
+	}
+	keys, err := mockKeyring(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	gw := mockGW.New(keys, creds.Host, l)
+	gw.HTTP = httpClient(def, cfg, l)
+	setEndpoint(&gw.Host, def, endpointHost)
+	gw.CallbackPath = callbackPath(def)
+
+	return gw, nil
+}
+
+func httpClient(def config.GatewayDefinition, cfg *config.Config, l log.Logger) *gatewayhttp.Client {
+	return gatewayhttp.New(def.Name, l, gatewayhttp.Settings{
+		Timeout:      cfg.GatewayHTTP.Timeout,
+		MaxRetries:   cfg.GatewayHTTP.MaxRetries,
+		RetryBackoff: cfg.GatewayHTTP.RetryBackoff,
+		RedactFields: append(slices.Clone(cfg.Log.RedactFields), cfg.GatewayHTTP.RedactFields...),
+	})
+}
+
+func environment(def config.GatewayDefinition) string {
+	if def.Environment == "" {
+		return config.EnvSandbox
+	}
+	return def.Environment
+}
+
+// callbackPath is where the HTTP server routes the instance's callbacks,
+// unless the definition overrides it.
+func callbackPath(def config.GatewayDefinition) string {
+	if v := def.Endpoints[endpointCallback]; v != "" {
+		return v
+	}
+	return "/callbacks/" + def.Name
+}
+
+// returnPath is where the HTTP server lands customers redirected back by the
+// instance's provider, unless the definition overrides it.
+func returnPath(def config.GatewayDefinition) string {
+	if v := def.Endpoints[endpointReturn]; v != "" {
+		return v
+	}
+	return "/returns/" + def.Name
+}
+
+func setEndpoint(field *string, def config.GatewayDefinition, name string) {
+	if v := def.Endpoints[name]; v != "" {
+		*field = v
+	}
+}
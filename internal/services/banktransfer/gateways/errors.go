@@ -0,0 +1,13 @@
+package gateways
+
+import "errors"
+
+var (
+	ErrUnknownType      = errors.New("unknown gateway type")
+	ErrInvalidName      = errors.New("gateway name is required")
+	ErrMissingSecret    = errors.New("gateway credentials are incomplete")
+	ErrNotInProduction  = errors.New("gateway type is not allowed in production")
+	ErrDuplicateGateway = errors.New("gateway defined more than once")
+	ErrSandboxInProd    = errors.New("sandbox gateway environment is not allowed in production")
+	ErrSandboxKeys      = errors.New("sandbox or default gateway keys are not allowed in production")
+)
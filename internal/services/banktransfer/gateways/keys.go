@@ -0,0 +1,77 @@
+package gateways
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+)
+
+// defaultKeyVersion names the key of credentials without a key set.
+const defaultKeyVersion = "default"
+
+// zalopayKeySet is one version of a ZaloPay app's key pair, as listed in
+// ZALOPAY_KEYS.
+type zalopayKeySet struct {
+	Version     string    `json:"version"`
+	Key1        string    `json:"key1"`
+	Key2        string    `json:"key2"`
+	ActivatesAt time.Time `json:"activates_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// zalopayKeySets reads ZALOPAY_KEYS, falling back to the single
+// ZALOPAY_KEY1/ZALOPAY_KEY2 pair.
+func zalopayKeySets(creds config.ZalopayConfig) ([]zalopayKeySet, error) {
+	if creds.KeysJSON == "" {
+		return []zalopayKeySet{{Version: defaultKeyVersion, Key1: creds.Key1, Key2: creds.Key2}}, nil
+	}
+
+	var sets []zalopayKeySet
+	if err := json.Unmarshal([]byte(creds.KeysJSON), &sets); err != nil {
+		return nil, fmt.Errorf("invalid ZALOPAY_KEYS: %w", err)
+	}
+	return sets, nil
+}
+
+func zalopayKeyrings(sets []zalopayKeySet) (*keyring.Keyring, *keyring.Keyring, error) {
+	var key1s, key2s []keyring.Key
+	for _, s := range sets {
+		key1s = append(key1s, keyring.Key{Version: s.Version, Secret: s.Key1, ActivatesAt: s.ActivatesAt, ExpiresAt: s.ExpiresAt})
+		key2s = append(key2s, keyring.Key{Version: s.Version, Secret: s.Key2, ActivatesAt: s.ActivatesAt, ExpiresAt: s.ExpiresAt})
+	}
+
+	key1, err := keyring.New(key1s...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: key1: %v", ErrMissingSecret, err)
+	}
+	key2, err := keyring.New(key2s...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: key2: %v", ErrMissingSecret, err)
+	}
+	return key1, key2, nil
+}
+
+// mockKeyring reads MOCK_GATEWAY_KEYS, falling back to MOCK_GATEWAY_SECRET.
+func mockKeyring(creds config.MockGatewayConfig) (*keyring.Keyring, error) {
+	if creds.KeysJSON == "" {
+		kr, err := keyring.Single(defaultKeyVersion, creds.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMissingSecret, err)
+		}
+		return kr, nil
+	}
+
+	var keys []keyring.Key
+	if err := json.Unmarshal([]byte(creds.KeysJSON), &keys); err != nil {
+		return nil, fmt.Errorf("invalid MOCK_GATEWAY_KEYS: %w", err)
+	}
+
+	kr, err := keyring.New(keys...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingSecret, err)
+	}
+	return kr, nil
+}
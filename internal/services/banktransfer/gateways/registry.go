@@ -0,0 +1,63 @@
+package gateways
+
+import (
+	"fmt"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/pkg/log"
+)
+
+// Constructor builds a gateway instance from its definition.
+type Constructor func(def config.GatewayDefinition, cfg *config.Config, l log.Logger) (bankTf.PaymentGateway, error)
+
+var constructors = map[string]Constructor{
+	string(models.GatewayTypeZalopay): newZalopay,
+	string(models.GatewayTypeCOD):     newCOD,
+	string(models.GatewayTypeMock):    newMock,
+}
+
+// productionBlocked lists gateway types that must never serve real payments.
+var productionBlocked = map[string]bool{
+	string(models.GatewayTypeMock): true,
+}
+
+// Register builds every enabled gateway definition and registers it under its
+// instance name. Any invalid definition fails the whole registration so a
+// misconfigured service refuses to start.
+func Register(gwf *bankTf.GatewayFactory, cfg *config.Config, l log.Logger) error {
+	seen := make(map[string]bool)
+	for _, def := range cfg.PayGateway.Definitions {
+		if def.Name == "" {
+			return fmt.Errorf("%w: type %q", ErrInvalidName, def.Type)
+		}
+		if seen[def.Name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateGateway, def.Name)
+		}
+		seen[def.Name] = true
+
+		newGW, ok := constructors[def.Type]
+		if !ok {
+			return fmt.Errorf("%w: %s (gateway %s)", ErrUnknownType, def.Type, def.Name)
+		}
+
+		if !def.Enabled {
+			continue
+		}
+		if productionBlocked[def.Type] && cfg.App.IsProduction() {
+			return fmt.Errorf("%w: %s (gateway %s)", ErrNotInProduction, def.Type, def.Name)
+		}
+
+		gw, err := newGW(def, cfg, l)
+		if err != nil {
+			return fmt.Errorf("gateway %s: %w", def.Name, err)
+		}
+
+		if err := gwf.RegisterGateway(models.GatewayType(def.Name), gw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
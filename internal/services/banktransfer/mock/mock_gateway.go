@@ -0,0 +1,185 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	PaymentPagePath = "/mock/pay/"
+
+	endpointCallback = "callback"
+
+	// metadataOutcome lets a test pick the outcome when creating the payment
+	// instead of calling the control API first.
+	metadataOutcome = "mock_outcome"
+)
+
+// ProcessPayment supports the web and QR modes; the QR code encodes the
+// payment page so a phone scanning it plays the scenario.
+func (g *MockGateway) ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest, ord *order.OrderData) (*payment.ProcessPaymentResponse, error) {
+	if req.Mode == payment.PaymentMode_PAYMENT_MODE_APP {
+		return nil, fmt.Errorf("%w: %s", bankTf.ErrModeNotSupported, req.Mode)
+	}
+
+	// The page charges the stored payment's amount, so only the outcome is
+	// passed along.
+	pageURL := g.Host + PaymentPagePath + url.PathEscape(req.OrderCode)
+	if o := req.Metadata[metadataOutcome]; o != "" {
+		pageURL += "?" + url.Values{"outcome": {o}}.Encode()
+	}
+
+	res := &payment.ProcessPaymentResponse{
+		PaymentUrl: pageURL,
+		Mode:       req.Mode,
+		Payment: &payment.PaymentData{
+			Id:              "MOCK_" + req.OrderCode,
+			OrderCode:       req.OrderCode,
+			UserId:          req.UserId,
+			Amount:          req.Amount,
+			Provider:        string(models.GatewayTypeMock),
+			ProviderDetails: req.ProviderDetails,
+			Metadata:        req.Metadata,
+		},
+	}
+	if req.Mode == payment.PaymentMode_PAYMENT_MODE_QR {
+		res.Payload = &payment.ProcessPaymentResponse_Qr{Qr: &payment.QRPayload{QrCode: pageURL}}
+	}
+
+	return res, nil
+}
+
+func (g *MockGateway) ParseCallback(ctx context.Context, r *http.Request) (bankTf.CallbackEvent, error) {
+	var cbData CallbackData
+	if err := json.NewDecoder(r.Body).Decode(&cbData); err != nil {
+		return bankTf.CallbackEvent{}, fmt.Errorf("%w: %v", bankTf.ErrInvalidCallback, err)
+	}
+
+	keyVersion, ok := g.Keys.Verify(cbData.Data, cbData.Mac)
+	if !ok {
+		return bankTf.CallbackEvent{}, bankTf.ErrInvalidSignature
+	}
+
+	var p callbackPayload
+	if err := json.Unmarshal([]byte(cbData.Data), &p); err != nil {
+		return bankTf.CallbackEvent{}, fmt.Errorf("%w: failed to parse callback data: %v", bankTf.ErrInvalidCallback, err)
+	}
+
+	ev := bankTf.CallbackEvent{
+		OrderCode:        p.OrderCode,
+		Status:           models.PaymentStatusCompleted,
+		GatewayReference: "MOCK_" + p.OrderCode,
+		Amount:           p.Amount,
+		KeyVersion:       keyVersion,
+	}
+	if p.Status != OutcomeSucceed {
+		ev.Status = models.PaymentStatusFailed
+		ev.Reason = fmt.Sprintf("mock outcome %s", p.Status)
+	}
+
+	return ev, nil
+}
+
+func (g *MockGateway) AcknowledgeCallback(w http.ResponseWriter, err error) {
+	bankTf.WriteJSONAck(w, err)
+}
+
+func (g *MockGateway) CancelPayment(ctx context.Context, req *payment.CancelPaymentRequest) (*emptypb.Empty, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.scenarios, req.GetOrderCode())
+
+	return &emptypb.Empty{}, nil
+}
+
+func (g *MockGateway) SetScenario(orderCode string, s Scenario) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.scenarios[orderCode] = s
+}
+
+// Scenario returns the scenario set for the order through the control API,
+// falling back to the outcome chosen at payment creation and then the default.
+func (g *MockGateway) Scenario(orderCode string, requested Outcome) Scenario {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if s, ok := g.scenarios[orderCode]; ok {
+		return s
+	}
+
+	s := g.DefaultScenario
+	if requested != "" {
+		s.Outcome = requested
+	}
+	return s
+}
+
+// Simulate plays the scenario for an order as if the user had paid on the
+// provider page. The callback is sent in the background after the delay.
+func (g *MockGateway) Simulate(orderCode string, amount float64, s Scenario) error {
+	switch s.Outcome {
+	case OutcomeNone:
+		return nil
+	case OutcomeSucceed, OutcomeFail:
+	default:
+		return fmt.Errorf("unknown mock outcome %q", s.Outcome)
+	}
+
+	data, err := json.Marshal(callbackPayload{
+		OrderCode: orderCode,
+		Amount:    amount,
+		Status:    s.Outcome,
+		PaidAt:    time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	mac, _, err := g.Keys.Sign(string(data))
+	if err != nil {
+		return err
+	}
+
+	cb := CallbackData{Data: string(data), Mac: mac}
+	if s.BadSignature {
+		cb.Mac = keyring.HMAC(mac, cb.Data)
+	}
+
+	go func() {
+		time.Sleep(s.Delay)
+		if err := g.sendCallback(cb); err != nil {
+			g.l.Warnf(context.Background(), "mock gateway: failed to deliver callback for %s: %v", orderCode, err)
+		}
+	}()
+
+	return nil
+}
+
+func (g *MockGateway) sendCallback(cb CallbackData) error {
+	body, err := json.Marshal(cb)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.HTTP.Do(context.Background(), gatewayhttp.Request{
+		Endpoint:    endpointCallback,
+		Method:      http.MethodPost,
+		URL:         g.Host + g.CallbackPath,
+		ContentType: "application/json",
+		Body:        body,
+	})
+	return err
+}
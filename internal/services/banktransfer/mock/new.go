@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/pkg/log"
+)
+
+type MockGateway struct {
+	Keys            *keyring.Keyring
+	Host            string
+	CallbackPath    string
+	DefaultScenario Scenario
+	HTTP            *gatewayhttp.Client
+
+	l         log.Logger
+	mu        sync.Mutex
+	scenarios map[string]Scenario
+}
+
+// New returns the concrete gateway rather than bankTf.PaymentGateway because
+// the HTTP server also drives its control API.
+func New(keys *keyring.Keyring, host string, l log.Logger) *MockGateway {
+	return &MockGateway{
+		Keys:            keys,
+		Host:            host,
+		CallbackPath:    "/callbacks/mock",
+		DefaultScenario: Scenario{Outcome: OutcomeSucceed},
+		HTTP:            gatewayhttp.New(string(models.GatewayTypeMock), l, gatewayhttp.Settings{}),
+		l:               l,
+		scenarios:       make(map[string]Scenario),
+	}
+}
+
+// Environment is always sandbox: the mock never moves real money.
+func (g *MockGateway) Environment() string {
+	return config.EnvSandbox
+}
+
+func (g *MockGateway) Routes() bankTf.Routes {
+	return bankTf.Routes{Callback: g.CallbackPath}
+}
+
+// PaymentMethod mirrors the online providers the mock stands in for.
+func (g *MockGateway) PaymentMethod() models.PaymentMethod {
+	return models.PaymentMethodOnline
+}
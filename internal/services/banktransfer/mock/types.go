@@ -0,0 +1,31 @@
+package mock
+
+import "time"
+
+type Outcome string
+
+const (
+	OutcomeSucceed Outcome = "succeed"
+	OutcomeFail    Outcome = "fail"
+	// OutcomeNone never calls back, to exercise missed-callback handling.
+	OutcomeNone Outcome = "none"
+)
+
+// Scenario decides what the mock provider does once the payment page is opened.
+type Scenario struct {
+	Outcome      Outcome
+	Delay        time.Duration
+	BadSignature bool
+}
+
+type CallbackData struct {
+	Data string `json:"data"`
+	Mac  string `json:"mac"`
+}
+
+type callbackPayload struct {
+	OrderCode string  `json:"order_code"`
+	Amount    float64 `json:"amount"`
+	Status    Outcome `json:"status"`
+	PaidAt    int64   `json:"paid_at"`
+}
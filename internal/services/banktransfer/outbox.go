@@ -0,0 +1,224 @@
+package banktransfer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/metrics"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.temporal.io/api/serviceerror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Dispatch outcomes, as counted by the callback_dispatches_total metric.
+const (
+	dispatchResultDispatched = "dispatched"
+	dispatchResultRetry      = "retry"
+	dispatchResultDeadLetter = "dead_letter"
+)
+
+// enqueueCallback stores the signal in the callback outbox. Legacy payments
+// without a record are keyed by order code.
+func (svc *implPaymentService) enqueueCallback(ctx context.Context, sig PaymentSignal) (models.CallbackOutboxEntry, error) {
+	key := sig.PaymentID
+	if key == "" {
+		key = sig.OrderCode
+	}
+
+	e, err := svc.outboxRepo.Enqueue(ctx, models.CallbackOutboxEntry{
+		Key:              key + ":" + string(sig.Status),
+		PaymentID:        sig.PaymentID,
+		OrderCode:        sig.OrderCode,
+		Provider:         sig.Provider,
+		Amount:           sig.Amount,
+		GatewayReference: sig.GatewayReference,
+		PaymentStatus:    sig.Status,
+		Reason:           sig.Reason,
+	})
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to queue callback for order %s: %v", sig.OrderCode, err)
+		return models.CallbackOutboxEntry{}, status.Errorf(codes.Internal, "failed to queue callback: %v", err)
+	}
+
+	return e, nil
+}
+
+// dispatchCallback sends a queued entry now if no one else holds it. The
+// dispatcher retries it on failure, so errors are only logged.
+func (svc *implPaymentService) dispatchCallback(ctx context.Context, id primitive.ObjectID) {
+	e, err := svc.outboxRepo.Claim(ctx, id, time.Now().Add(svc.outboxCfg.Lease))
+	if errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		svc.l.Warnf(ctx, "failed to claim callback %s: %v", id.Hex(), err)
+		return
+	}
+	svc.dispatchEntry(ctx, e)
+}
+
+// dispatchEntry signals the order's workflow, and starts compensation for
+// failed payments, from a claimed entry. A workflow already started for the
+// entry counts as dispatched.
+func (svc *implPaymentService) dispatchEntry(ctx context.Context, e models.CallbackOutboxEntry) error {
+	sig := PaymentSignal{
+		PaymentID:        e.PaymentID,
+		OrderCode:        e.OrderCode,
+		Provider:         e.Provider,
+		Amount:           e.Amount,
+		GatewayReference: e.GatewayReference,
+		Status:           e.PaymentStatus,
+		Reason:           e.Reason,
+	}
+
+	var err error
+	if sig.Status == models.PaymentStatusFailed {
+		err = svc.signalPaymentFailed(ctx, sig)
+		if err == nil {
+			err = svc.startCompensation(ctx, sig)
+		}
+	} else {
+		err = svc.signalPaymentCompleted(ctx, sig)
+	}
+	var started *serviceerror.WorkflowExecutionAlreadyStarted
+	if err != nil && !errors.As(err, &started) {
+		svc.failDispatch(ctx, e, err)
+		return err
+	}
+
+	if id, err := primitive.ObjectIDFromHex(e.PaymentID); err == nil {
+		svc.markSignalled(ctx, models.Payment{ID: id, Status: e.PaymentStatus})
+	}
+	if err := svc.outboxRepo.MarkDispatched(ctx, e.ID); err != nil {
+		// The signal went out; a repeated one is deduplicated downstream.
+		svc.l.Warnf(ctx, "failed to mark callback %s dispatched: %v", e.ID.Hex(), err)
+	}
+	metrics.CallbackDispatches.WithLabelValues(dispatchResultDispatched).Inc()
+	return nil
+}
+
+func (svc *implPaymentService) failDispatch(ctx context.Context, e models.CallbackOutboxEntry, cause error) {
+	opts := repository.MarkFailedOptions{
+		Error:         cause.Error(),
+		NextAttemptAt: time.Now().Add(dispatchBackoff(svc.outboxCfg, e.Attempts)),
+		DeadLetter:    e.Attempts >= svc.outboxCfg.MaxAttempts,
+	}
+
+	if opts.DeadLetter {
+		metrics.CallbackDispatches.WithLabelValues(dispatchResultDeadLetter).Inc()
+		svc.l.Errorf(ctx, "callback for order %s dead-lettered after %d attempts: %v", e.OrderCode, e.Attempts, cause)
+	} else {
+		metrics.CallbackDispatches.WithLabelValues(dispatchResultRetry).Inc()
+		svc.l.Warnf(ctx, "failed to dispatch callback for order %s, attempt %d: %v", e.OrderCode, e.Attempts, cause)
+	}
+
+	if err := svc.outboxRepo.MarkFailed(ctx, e.ID, opts); err != nil {
+		svc.l.Errorf(ctx, "failed to reschedule callback %s: %v", e.ID.Hex(), err)
+	}
+}
+
+// dispatchBackoff doubles from RetryInitialInterval after each attempt, up
+// to RetryMaxInterval.
+func dispatchBackoff(cfg config.OutboxConfig, attempts int) time.Duration {
+	d := cfg.RetryInitialInterval
+	for i := 1; i < attempts && d < cfg.RetryMaxInterval; i++ {
+		d *= 2
+	}
+	return min(d, cfg.RetryMaxInterval)
+}
+
+// CallbackDispatcher drains the callback outbox. Any number of dispatchers
+// may run: each entry is claimed by one at a time.
+type CallbackDispatcher struct {
+	svc *implPaymentService
+}
+
+func NewCallbackDispatcher(svc payment.PaymentServiceServer) (*CallbackDispatcher, error) {
+	impl, ok := svc.(*implPaymentService)
+	if !ok {
+		return nil, errors.New("invalid payment service implementation")
+	}
+	return &CallbackDispatcher{svc: impl}, nil
+}
+
+// Run dispatches due entries every DispatchInterval until ctx is done.
+func (d *CallbackDispatcher) Run(ctx context.Context) {
+	t := time.NewTicker(d.svc.outboxCfg.DispatchInterval)
+	defer t.Stop()
+
+	for {
+		d.DispatchDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// DispatchDue dispatches up to BatchSize due entries and returns how many
+// it tried.
+func (d *CallbackDispatcher) DispatchDue(ctx context.Context) int {
+	n := 0
+	for ; n < d.svc.outboxCfg.BatchSize && ctx.Err() == nil; n++ {
+		e, err := d.svc.outboxRepo.ClaimNext(ctx, time.Now().Add(d.svc.outboxCfg.Lease))
+		if errors.Is(err, repository.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			d.svc.l.Errorf(ctx, "failed to claim callback: %v", err)
+			break
+		}
+		d.svc.dispatchEntry(ctx, e)
+	}
+	return n
+}
+
+// List returns outbox entries, oldest first, optionally in one status.
+func (d *CallbackDispatcher) List(ctx context.Context, st models.OutboxStatus, limit int64) ([]models.CallbackOutboxEntry, error) {
+	es, err := d.svc.outboxRepo.List(ctx, repository.ListOutboxOptions{Status: st, Limit: limit})
+	if err != nil {
+		d.svc.l.Errorf(ctx, "failed to list callback outbox: %v", err)
+		return nil, ErrInternal
+	}
+	return es, nil
+}
+
+// Redrive queues a dead-lettered entry again and dispatches it right away.
+// It returns the entry as it stands afterwards.
+func (d *CallbackDispatcher) Redrive(ctx context.Context, id string) (models.CallbackOutboxEntry, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.CallbackOutboxEntry{}, ErrOutboxEntryNotFound
+	}
+
+	e, err := d.svc.outboxRepo.Redrive(ctx, oid)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return models.CallbackOutboxEntry{}, ErrOutboxEntryNotFound
+	case errors.Is(err, repository.ErrStatusConflict):
+		return models.CallbackOutboxEntry{}, ErrOutboxEntryNotDead
+	case err != nil:
+		d.svc.l.Errorf(ctx, "failed to re-drive callback %s: %v", id, err)
+		return models.CallbackOutboxEntry{}, ErrInternal
+	}
+	d.svc.l.Infof(ctx, "re-driving callback %s for order %s", id, e.OrderCode)
+
+	e, err = d.svc.outboxRepo.Claim(ctx, oid, time.Now().Add(d.svc.outboxCfg.Lease))
+	if err != nil {
+		// Claimed by a dispatcher in between; it sends the entry.
+		return e, nil
+	}
+	if err := d.svc.dispatchEntry(ctx, e); err != nil {
+		e.LastError = err.Error()
+		return e, nil
+	}
+	e.Status = models.OutboxStatusDispatched
+	return e, nil
+}
@@ -0,0 +1,91 @@
+package banktransfer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResolveReturn verifies the redirect a customer lands on the return URL
+// with and works out where their payment stands. A payment the callback or
+// the poller already settled keeps its recorded status. Otherwise, with
+// confirm set, the provider's status API has the last word and an
+// unanswered query leaves the payment pending; without it the verified
+// redirect is taken as is. Nothing is recorded: settling payments stays
+// with callbacks and the poller.
+func (svc *implPaymentService) ResolveReturn(ctx context.Context, gatewayType models.GatewayType, r *http.Request, confirm bool) (ReturnResult, error) {
+	gw, err := svc.gwf.GetGateway(gatewayType)
+	if err != nil {
+		svc.l.Warnf(ctx, "return for unknown provider %s: %v", gatewayType, err)
+		return ReturnResult{}, ErrInvalidGateway
+	}
+
+	rv, ok := gw.(RedirectVerifier)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", gatewayType, ErrRedirectNotSupported)
+		return ReturnResult{}, ErrRedirectNotSupported
+	}
+
+	ev, err := rv.ParseRedirect(ctx, r)
+	if err != nil {
+		svc.l.Warnf(ctx, "rejected %s return redirect: %v", gatewayType, err)
+		return ReturnResult{}, err
+	}
+
+	p, err := svc.repo.FindByOrderCode(ctx, ev.OrderCode)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		// Orders paid before payments were persisted only have the redirect.
+		p = models.Payment{
+			OrderCode:        ev.OrderCode,
+			Provider:         gatewayType,
+			GatewayReference: ev.GatewayReference,
+			Status:           models.PaymentStatusPending,
+		}
+	case err != nil:
+		svc.l.Errorf(ctx, "failed to find payment for order %s: %v", ev.OrderCode, err)
+		return ReturnResult{}, ErrInternal
+	}
+
+	if p.Amount > 0 && ev.Amount > 0 && ev.Amount != p.Amount {
+		svc.l.Warnf(ctx, "return redirect for order %s paid %.0f of %.0f: %v", ev.OrderCode, ev.Amount, p.Amount, ErrAmountMismatch)
+		return ReturnResult{}, ErrAmountMismatch
+	}
+
+	res := ReturnResult{OrderCode: ev.OrderCode, Status: ev.Status}
+	switch {
+	case p.Status != models.PaymentStatusPending:
+		res.Status = p.Status
+		res.Confirmed = true
+	case confirm:
+		if p.GatewayReference == "" {
+			p.GatewayReference = ev.GatewayReference
+		}
+		st, err := svc.queryGatewayStatus(ctx, p)
+		if err != nil {
+			svc.l.Warnf(ctx, "could not confirm %s return for order %s: %v", gatewayType, ev.OrderCode, err)
+			res.Status = models.PaymentStatusPending
+			break
+		}
+		res.Status = st
+		res.Confirmed = true
+	}
+
+	return res, nil
+}
+
+// ResolvePaymentReturn is ResolveReturn for callers holding the service as
+// a payment.PaymentServiceServer.
+func ResolvePaymentReturn(ctx context.Context, svc payment.PaymentServiceServer, gatewayType models.GatewayType, r *http.Request, confirm bool) (ReturnResult, error) {
+	impl, ok := svc.(*implPaymentService)
+	if !ok {
+		return ReturnResult{}, status.Errorf(codes.Internal, "invalid payment service implementation")
+	}
+	return impl.ResolveReturn(ctx, gatewayType, r, confirm)
+}
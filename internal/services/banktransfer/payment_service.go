@@ -2,32 +2,59 @@ package banktransfer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
 	"time"
 
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/metrics"
 	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
 	"github.com/vogiaan1904/payment-svc/pkg/log"
 	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
 	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type implPaymentService struct {
-	l        log.Logger
-	gwf      *GatewayFactory
-	orderSvc order.OrderServiceClient
-	temporal client.Client
+	l          log.Logger
+	gwf        *GatewayFactory
+	router     *GatewayRouter
+	health     *GatewayHealth
+	orderSvc   order.OrderServiceClient
+	temporal   client.Client
+	wfCfg      config.TemporalConfig
+	outboxCfg  config.OutboxConfig
+	repo       repository.PaymentRepository
+	reviewRepo repository.StatementReviewRepository
+	outboxRepo repository.CallbackOutboxRepository
+	csvLayout  statement.CSVLayout
 	payment.UnimplementedPaymentServiceServer
 }
 
-func NewPaymentService(l log.Logger, gwf *GatewayFactory, orderSvc order.OrderServiceClient, temporal client.Client) payment.PaymentServiceServer {
+func NewPaymentService(l log.Logger, gwf *GatewayFactory, router *GatewayRouter, health *GatewayHealth, orderSvc order.OrderServiceClient, temporal client.Client, wfCfg config.TemporalConfig, outboxCfg config.OutboxConfig, repo repository.PaymentRepository, reviewRepo repository.StatementReviewRepository, outboxRepo repository.CallbackOutboxRepository, csvLayout statement.CSVLayout) payment.PaymentServiceServer {
 	return &implPaymentService{
-		l:        l,
-		gwf:      gwf,
-		orderSvc: orderSvc,
-		temporal: temporal,
+		l:          l,
+		gwf:        gwf,
+		router:     router,
+		health:     health,
+		orderSvc:   orderSvc,
+		temporal:   temporal,
+		wfCfg:      wfCfg,
+		outboxCfg:  outboxCfg,
+		repo:       repo,
+		reviewRepo: reviewRepo,
+		outboxRepo: outboxRepo,
+		csvLayout:  csvLayout,
 	}
 }
 
@@ -48,21 +75,141 @@ func (svc *implPaymentService) ProcessPayment(ctx context.Context, req *payment.
 		return nil, status.Error(codes.FailedPrecondition, ErrOrderNotPending.Error())
 	}
 
-	gw, err := svc.gwf.GetGateway(models.GatewayType(req.Provider))
+	currency := req.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	candidates, err := svc.router.Route(RouteRequest{
+		Provider: models.GatewayType(req.Provider),
+		Amount:   req.Amount,
+		Currency: currency,
+		Segment:  req.UserSegment,
+	})
 	if err != nil {
-		svc.l.Warnf(ctx, "failed to get payment gateway: %v", err)
+		svc.l.Warnf(ctx, "failed to route payment: %v", err)
+		if errors.Is(err, ErrNoEligibleGateway) {
+			return nil, status.Error(codes.FailedPrecondition, ErrNoEligibleGateway.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, ErrInvalidGateway.Error())
 	}
 
-	pRes, err := gw.ProcessPayment(ctx, req)
+	routing := &models.RoutingDecision{
+		Explicit:   req.Provider != "",
+		Candidates: candidates,
+	}
+
+	var (
+		pRes *payment.ProcessPaymentResponse
+		gt   models.GatewayType
+		gw   PaymentGateway
+	)
+	for _, gt = range candidates {
+		gw, err = svc.gwf.GetGateway(gt)
+		if err != nil {
+			svc.l.Errorf(ctx, "failed to get payment gateway: %v", err)
+			return nil, status.Error(codes.Internal, ErrInternal.Error())
+		}
+
+		gReq := proto.Clone(req).(*payment.ProcessPaymentRequest)
+		gReq.Provider = string(gt)
+		gReq.Currency = currency
+
+		err = svc.health.Do(gt, func() error {
+			var pErr error
+			pRes, pErr = gw.ProcessPayment(ctx, gReq, res.Order)
+			return pErr
+		})
+		attempt := models.RoutingAttempt{Provider: gt}
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		routing.Attempts = append(routing.Attempts, attempt)
+
+		if err == nil {
+			break
+		}
+		if errors.Is(err, ErrModeNotSupported) {
+			svc.l.Warnf(ctx, "gateway %s: %v", gt, err)
+			return nil, status.Errorf(codes.InvalidArgument, "gateway %s: %v", gt, ErrModeNotSupported)
+		}
+		if errors.Is(err, ErrInvalidInput) {
+			svc.l.Warnf(ctx, "gateway %s rejected the request: %v", gt, err)
+			return nil, status.Errorf(codes.InvalidArgument, "gateway %s: %v", gt, err)
+		}
+		if !IsRetryable(err) {
+			svc.l.Errorf(ctx, "payment processing failed: %v", err)
+			return nil, status.Error(codes.Internal, ErrInternal.Error())
+		}
+		svc.l.Warnf(ctx, "gateway %s unavailable, failing over: %v", gt, err)
+	}
+
+	if pRes == nil {
+		svc.l.Errorf(ctx, "payment processing failed on all gateways: %v", candidates)
+		return nil, status.Error(codes.Unavailable, ErrGatewayUnavailable.Error())
+	}
+
+	saved, err := svc.repo.Create(ctx, models.Payment{
+		OrderID:          res.Order.Id,
+		OrderCode:        req.OrderCode,
+		UserID:           req.UserId,
+		Amount:           req.Amount,
+		Currency:         currency,
+		Status:           models.PaymentStatusPending,
+		Method:           MethodOf(gw),
+		Provider:         gt,
+		GatewayReference: pRes.GetPayment().GetId(),
+		Metadata:         req.Metadata,
+		Routing:          routing,
+	})
 	if err != nil {
-		svc.l.Errorf(ctx, "payment processing failed: %v", err)
+		svc.l.Errorf(ctx, "failed to save payment: %v", err)
 		return nil, status.Error(codes.Internal, ErrInternal.Error())
 	}
 
+	if _, ok := gw.(StatusQuerier); ok {
+		svc.startStatusPoll(ctx, saved)
+	}
+
 	return pRes, nil
 }
 
+// startStatusPoll starts the worker's poller for a new payment. The
+// callback still completes the payment without it, so failing to start is
+// only logged.
+func (svc *implPaymentService) startStatusPoll(ctx context.Context, p models.Payment) {
+	if svc.wfCfg.PollTimeout <= 0 {
+		return
+	}
+
+	wfID := WorkflowPaymentPollPrefix + p.ID.Hex()
+	we, err := svc.temporal.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        wfID,
+		TaskQueue: svc.wfCfg.PaymentTaskQueue,
+		TypedSearchAttributes: svc.searchAttributes(PaymentSignal{
+			PaymentID: p.ID.Hex(),
+			OrderCode: p.OrderCode,
+			Provider:  string(p.Provider),
+			Amount:    p.Amount,
+			Status:    p.Status,
+		}),
+	}, WorkflowPollPaymentStatus, PollPaymentParams{
+		PaymentID:       p.ID.Hex(),
+		OrderCode:       p.OrderCode,
+		Provider:        string(p.Provider),
+		Amount:          p.Amount,
+		InitialInterval: svc.wfCfg.PollInitialInterval,
+		MaxInterval:     svc.wfCfg.PollMaxInterval,
+		ExpiresAt:       time.Now().Add(svc.wfCfg.PollTimeout),
+	})
+	if err != nil {
+		svc.l.Errorf(ctx, "Failed to start status poll for payment %s: %v", p.ID.Hex(), err)
+		return
+	}
+
+	svc.l.Infof(ctx, "Status poll started. WorkflowID: %s, RunID: %s", we.GetID(), we.GetRunID())
+}
+
 func (svc *implPaymentService) CancelPayment(ctx context.Context, req *payment.CancelPaymentRequest) (*emptypb.Empty, error) {
 	resp, err := svc.orderSvc.FindOne(ctx, &order.FindOneRequest{Request: &order.FindOneRequest_Code{Code: req.GetOrderCode()}})
 	if err != nil {
@@ -76,7 +223,22 @@ func (svc *implPaymentService) CancelPayment(ctx context.Context, req *payment.C
 		return nil, status.Errorf(codes.InvalidArgument, "invalid gateway: %v", err)
 	}
 
-	cRes, err := gw.CancelPayment(ctx, req)
+	cnl, ok := gw.(Canceller)
+	if !ok {
+		svc.l.Warnf(ctx, "gateway %s: %v", resp.Order.Provider, ErrCancelNotSupported)
+		return nil, status.Errorf(codes.FailedPrecondition, "gateway %s: %v", resp.Order.Provider, ErrCancelNotSupported)
+	}
+
+	var cRes *emptypb.Empty
+	err = svc.health.Do(models.GatewayType(resp.Order.Provider), func() error {
+		var cErr error
+		cRes, cErr = cnl.CancelPayment(ctx, req)
+		return cErr
+	})
+	if IsRetryable(err) {
+		svc.l.Warnf(ctx, "failed to cancel payment: %v", err)
+		return nil, status.Error(codes.Unavailable, ErrGatewayUnavailable.Error())
+	}
 	if err != nil {
 		svc.l.Errorf(ctx, "failed to cancel payment: %v", err)
 		return nil, status.Errorf(codes.Internal, "failed to cancel payment: %v", err)
@@ -85,47 +247,243 @@ func (svc *implPaymentService) CancelPayment(ctx context.Context, req *payment.C
 	return cRes, nil
 }
 
-func (svc *implPaymentService) HandleCallback(ctx context.Context, data interface{}, gatewayType models.GatewayType) error {
+// HandleCallback lets the provider's gateway parse the raw request, applies
+// the result and has the gateway acknowledge it. ErrInvalidGateway is
+// returned before anything is written, so the caller can answer 404.
+func (svc *implPaymentService) HandleCallback(ctx context.Context, gatewayType models.GatewayType, w http.ResponseWriter, r *http.Request) error {
 	gw, err := svc.gwf.GetGateway(gatewayType)
 	if err != nil {
-		svc.l.Errorf(ctx, "failed to get payment gateway: %v", err)
-		return status.Errorf(codes.InvalidArgument, "invalid gateway: %v", err)
+		svc.l.Warnf(ctx, "callback for unknown provider %s: %v", gatewayType, err)
+		return ErrInvalidGateway
+	}
+
+	ev, err := gw.ParseCallback(ctx, r)
+	switch {
+	case errors.Is(err, ErrInvalidSignature):
+		metrics.CallbackVerifications.WithLabelValues(string(gatewayType), "", "invalid").Inc()
+		svc.l.Warnf(ctx, "rejected %s callback: %v", gatewayType, err)
+	case err != nil:
+		svc.l.Warnf(ctx, "rejected %s callback: %v", gatewayType, err)
+	default:
+		if ev.KeyVersion != "" {
+			metrics.CallbackVerifications.WithLabelValues(string(gatewayType), ev.KeyVersion, "valid").Inc()
+		}
+		err = svc.applyCallback(ctx, ev)
 	}
 
-	oCode, err := gw.HandleCallback(ctx, data)
+	gw.AcknowledgeCallback(w, err)
+	if errors.Is(err, ErrDuplicateCallback) {
+		svc.l.Infof(ctx, "ignored duplicate %s callback", gatewayType)
+		return nil
+	}
+	return err
+}
+
+// applyCallback records the callback and queues the signal to the order's
+// workflow in the callback outbox, then tries to send it right away. Once
+// queued the callback is safe: a signal failing now, e.g. while Temporal is
+// down, is retried by the outbox dispatcher. Callbacks and the status poller
+// race to complete a payment: whoever moves it out of pending queues the
+// signal, the other gets ErrDuplicateCallback. A payment already in the
+// event's status that was never signalled is queued again, which is a no-op
+// while its entry is pending.
+func (svc *implPaymentService) applyCallback(ctx context.Context, ev CallbackEvent) error {
+	p, err := svc.recordCallback(ctx, ev)
 	if err != nil {
-		svc.l.Errorf(ctx, "failed to handle callback: %v", err)
-		return status.Errorf(codes.Internal, "failed to handle callback: %v", err)
+		return err
 	}
 
-	wfID := "order_post_payment_" + oCode
-	wfParams := OrderWorkflowParams{
-		OrderCode: oCode,
+	sig := PaymentSignal{
+		PaymentID:        p.ID.Hex(),
+		OrderCode:        ev.OrderCode,
+		Provider:         string(p.Provider),
+		Amount:           ev.Amount,
+		GatewayReference: ev.GatewayReference,
+		Status:           ev.Status,
+		Reason:           ev.Reason,
+	}
+	if p.ID.IsZero() {
+		sig.PaymentID = ""
+	}
+	if sig.Amount == 0 {
+		sig.Amount = p.Amount
 	}
 
+	e, err := svc.enqueueCallback(ctx, sig)
+	if err != nil {
+		return err
+	}
+	svc.dispatchCallback(ctx, e.ID)
+	return nil
+}
+
+// recordCallback moves the payment to the event's status and returns it.
+// The payment is empty for orders paid before payments were persisted.
+func (svc *implPaymentService) recordCallback(ctx context.Context, ev CallbackEvent) (models.Payment, error) {
+	p, err := svc.repo.FindByOrderCode(ctx, ev.OrderCode)
+	switch {
+	case errors.Is(err, repository.ErrNotFound) && ev.Status != models.PaymentStatusFailed:
+		// Payments created before they were persisted only exist in the
+		// order service; the workflow still completes those.
+		svc.l.Warnf(ctx, "payment for order %s: %v", ev.OrderCode, ErrPaymentNotFound)
+		return models.Payment{OrderCode: ev.OrderCode}, nil
+	case err != nil:
+		svc.l.Errorf(ctx, "failed to find payment for order %s: %v", ev.OrderCode, err)
+		return models.Payment{}, status.Errorf(codes.Internal, "failed to find payment: %v", err)
+	}
+
+	// Whatever the gateway, a payment completes only for its own amount.
+	if ev.Status == models.PaymentStatusCompleted && math.Abs(ev.Amount-p.Amount) >= amountTolerance {
+		svc.l.Warnf(ctx, "callback for order %s paid %.0f of %.0f: %v", ev.OrderCode, ev.Amount, p.Amount, ErrAmountMismatch)
+		return models.Payment{}, fmt.Errorf("%w: %w", ErrInvalidCallback, ErrAmountMismatch)
+	}
+
+	if p.Status == ev.Status {
+		if p.Metadata[metadataSignalled] == string(ev.Status) {
+			return p, ErrDuplicateCallback
+		}
+		return p, nil
+	}
+
+	opts := repository.UpdateStatusOptions{
+		Status:           ev.Status,
+		From:             []models.PaymentStatus{models.PaymentStatusPending},
+		GatewayReference: ev.GatewayReference,
+	}
+	if ev.Status == models.PaymentStatusFailed {
+		svc.l.Warnf(ctx, "payment for order %s declined: %s", ev.OrderCode, ev.Reason)
+		opts.Metadata = map[string]string{metadataFailureReason: ev.Reason}
+	} else {
+		// The provider took the money, so a success outranks a failure
+		// seen earlier, e.g. by a poll racing the payment page.
+		opts.From = append(opts.From, models.PaymentStatusFailed)
+	}
+
+	up, err := svc.repo.UpdateStatus(ctx, p.ID, opts)
+	if errors.Is(err, repository.ErrStatusConflict) {
+		svc.l.Infof(ctx, "payment for order %s already left pending", ev.OrderCode)
+		return p, ErrDuplicateCallback
+	}
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to update payment for order %s: %v", ev.OrderCode, err)
+		return models.Payment{}, status.Errorf(codes.Internal, "failed to update payment: %v", err)
+	}
+
+	return up, nil
+}
+
+// markSignalled records which status the order's workflow was told about,
+// unless the payment moved on since. Failing to record it only risks a
+// repeated signal.
+func (svc *implPaymentService) markSignalled(ctx context.Context, p models.Payment) {
+	if p.ID.IsZero() {
+		return
+	}
+	if _, err := svc.repo.UpdateStatus(ctx, p.ID, repository.UpdateStatusOptions{
+		Status:   p.Status,
+		From:     []models.PaymentStatus{p.Status},
+		Metadata: map[string]string{metadataSignalled: string(p.Status)},
+	}); err != nil && !errors.Is(err, repository.ErrStatusConflict) {
+		svc.l.Warnf(ctx, "failed to mark payment %s signalled: %v", p.ID.Hex(), err)
+	}
+}
+
+// gatewayError maps a failed gateway call to a gRPC status: Unavailable when
+// the provider could not be reached or its breaker is open, Internal otherwise.
+func (svc *implPaymentService) gatewayError(ctx context.Context, op string, err error) error {
+	if IsRetryable(err) {
+		svc.l.Warnf(ctx, "failed to %s: %v", op, err)
+		return status.Error(codes.Unavailable, ErrGatewayUnavailable.Error())
+	}
+
+	svc.l.Errorf(ctx, "failed to %s: %v", op, err)
+	return status.Error(codes.Internal, ErrInternal.Error())
+}
+
+func (svc *implPaymentService) findPayment(ctx context.Context, orderCode string) (models.Payment, error) {
+	p, err := svc.repo.FindByOrderCode(ctx, orderCode)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			svc.l.Warnf(ctx, "payment for order %s: %v", orderCode, ErrPaymentNotFound)
+			return models.Payment{}, status.Error(codes.NotFound, ErrPaymentNotFound.Error())
+		}
+		svc.l.Errorf(ctx, "failed to find payment: %v", err)
+		return models.Payment{}, status.Error(codes.Internal, ErrInternal.Error())
+	}
+
+	return p, nil
+}
+
+// signalPaymentCompleted tells the order's pre-payment workflow the payment
+// went through. Once that workflow is gone, e.g. for COD collected at
+// delivery, the post-payment workflow is started with the signal instead.
+// An order is post-processed once: starting it again fails with
+// WorkflowExecutionAlreadyStarted, which callers treat as a duplicate.
+func (svc *implPaymentService) signalPaymentCompleted(ctx context.Context, sig PaymentSignal) error {
+	preID := WorkflowPrePaymentPrefix + sig.OrderCode
+	err := svc.temporal.SignalWorkflow(ctx, preID, "", SignalNamePaymentCompleted, sig)
+	var notFound *serviceerror.NotFound
+	if !errors.As(err, &notFound) {
+		if err != nil {
+			svc.l.Errorf(ctx, "Failed to signal workflow %s: %v", preID, err)
+			return err
+		}
+		svc.l.Infof(ctx, "Signalled %s to workflow %s", SignalNamePaymentCompleted, preID)
+		return nil
+	}
+
+	wfID := WorkflowPostPaymentPrefix + sig.OrderCode
 	wfOpts := client.StartWorkflowOptions{
 		ID:                       wfID,
-		TaskQueue:                TaskQueueName,
-		WorkflowExecutionTimeout: time.Hour * 24,
-		WorkflowRunTimeout:       time.Hour * 24,
-		WorkflowTaskTimeout:      time.Minute * 1,
+		TaskQueue:                svc.wfCfg.OrderTaskQueue,
+		WorkflowExecutionTimeout: svc.wfCfg.WorkflowExecutionTimeout,
+		WorkflowRunTimeout:       svc.wfCfg.WorkflowRunTimeout,
+		WorkflowTaskTimeout:      svc.wfCfg.WorkflowTaskTimeout,
+		WorkflowIDReusePolicy:    enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+		TypedSearchAttributes:    svc.searchAttributes(sig),
 	}
 
-	svc.l.Infof(ctx, "Starting workflow with ID: %s", wfID)
-	we, err := svc.temporal.ExecuteWorkflow(ctx, wfOpts, WorkflowName, wfParams)
+	svc.l.Infof(ctx, "Workflow %s not running, starting workflow with ID: %s", preID, wfID)
+	we, err := svc.temporal.SignalWithStartWorkflow(ctx, wfID, SignalNamePaymentCompleted, sig, wfOpts, WorkflowName, OrderWorkflowParams{
+		OrderCode: sig.OrderCode,
+	})
+	var started *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &started) {
+		svc.l.Warnf(ctx, "Workflow %s already started", wfID)
+		return err
+	}
 	if err != nil {
 		svc.l.Errorf(ctx, "Failed to start workflow: %v", err)
-		return status.Errorf(codes.Internal, "failed to initiate order processing: %v", err)
+		return err
 	}
 
 	svc.l.Infof(ctx, "Workflow started successfully. WorkflowID: %s, RunID: %s", we.GetID(), we.GetRunID())
 	return nil
 }
 
-func HandlePaymentCallback(svc payment.PaymentServiceServer, ctx context.Context, data interface{}, gatewayType models.GatewayType) error {
+// signalPaymentFailed tells the order's pre-payment workflow the payment
+// failed, if it is still running; compensation runs either way.
+func (svc *implPaymentService) signalPaymentFailed(ctx context.Context, sig PaymentSignal) error {
+	preID := WorkflowPrePaymentPrefix + sig.OrderCode
+	err := svc.temporal.SignalWorkflow(ctx, preID, "", SignalNamePaymentFailed, sig)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		svc.l.Warnf(ctx, "Workflow %s not running, payment failure not signalled", preID)
+		return nil
+	}
+	if err != nil {
+		svc.l.Errorf(ctx, "Failed to signal workflow %s: %v", preID, err)
+		return err
+	}
+
+	svc.l.Infof(ctx, "Signalled %s to workflow %s", SignalNamePaymentFailed, preID)
+	return nil
+}
+
+func HandlePaymentCallback(svc payment.PaymentServiceServer, ctx context.Context, gatewayType models.GatewayType, w http.ResponseWriter, r *http.Request) error {
 	impl, ok := svc.(*implPaymentService)
 	if !ok {
 		return status.Errorf(codes.Internal, "invalid payment service implementation")
 	}
-	return impl.HandleCallback(ctx, data, gatewayType)
+	return impl.HandleCallback(ctx, gatewayType, w, r)
 }
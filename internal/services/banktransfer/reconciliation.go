@@ -0,0 +1,324 @@
+package banktransfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/repository"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type matchKind int
+
+const (
+	matchNone matchKind = iota
+	matchExact
+	matchReview
+)
+
+type statementMatch struct {
+	kind       matchKind
+	payment    models.Payment
+	reason     models.ReviewReason
+	candidates []primitive.ObjectID
+}
+
+func (svc *implPaymentService) ImportBankStatement(ctx context.Context, req *payment.ImportBankStatementRequest) (*payment.ImportBankStatementResponse, error) {
+	format := models.StatementFormat(req.Format)
+	p, err := statement.NewParser(format, svc.csvLayout)
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to create statement parser: %v", err)
+		return nil, status.Error(codes.InvalidArgument, ErrInvalidStatement.Error())
+	}
+
+	txs, err := p.Parse(bytes.NewReader(req.Content))
+	if err != nil {
+		svc.l.Warnf(ctx, "failed to parse %s statement: %v", format, err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v: %v", ErrInvalidStatement, err)
+	}
+
+	pending, err := svc.repo.List(ctx, repository.ListPaymentsOptions{
+		Status: models.PaymentStatusPending,
+		Method: models.PaymentMethodBankTransfer,
+	})
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to list pending payments: %v", err)
+		return nil, status.Error(codes.Internal, ErrInternal.Error())
+	}
+	pending = svc.transferCandidates(pending)
+
+	res := &payment.ImportBankStatementResponse{Total: int32(len(txs))}
+	for _, tx := range txs {
+		if !tx.Credit {
+			continue
+		}
+
+		prev, err := svc.reconciledPayment(ctx, tx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, ErrInternal.Error())
+		}
+		if !prev.ID.IsZero() {
+			requeued, err := svc.requeueTransfer(ctx, prev, tx)
+			if err != nil {
+				return nil, status.Error(codes.Internal, ErrInternal.Error())
+			}
+			if requeued {
+				res.Completed++
+				res.CompletedOrderCodes = append(res.CompletedOrderCodes, prev.OrderCode)
+			}
+			continue
+		}
+
+		m := matchTransaction(tx, pending)
+		switch m.kind {
+		case matchExact:
+			err := svc.completeTransfer(ctx, m.payment, tx)
+			if err != nil && !errors.Is(err, ErrDuplicateCallback) {
+				return nil, status.Error(codes.Internal, ErrInternal.Error())
+			}
+			pending = removePayment(pending, m.payment.ID)
+			if err != nil {
+				// A callback or another import completed it in the meantime.
+				res.Unmatched++
+				continue
+			}
+			res.Completed++
+			res.CompletedOrderCodes = append(res.CompletedOrderCodes, m.payment.OrderCode)
+		case matchReview:
+			if _, err := svc.reviewRepo.Upsert(ctx, models.StatementReview{
+				TransactionID:     tx.Key(),
+				Format:            format,
+				BookingDate:       tx.BookingDate,
+				Amount:            tx.Amount,
+				Currency:          tx.Currency,
+				Reference:         tx.Reference,
+				Description:       tx.Description,
+				Counterparty:      tx.Counterparty,
+				Reason:            m.reason,
+				CandidatePayments: m.candidates,
+			}); err != nil {
+				svc.l.Errorf(ctx, "failed to queue statement review: %v", err)
+				return nil, status.Error(codes.Internal, ErrInternal.Error())
+			}
+			res.Review++
+		default:
+			res.Unmatched++
+		}
+	}
+
+	svc.l.Infof(ctx, "Imported %s statement: total=%d completed=%d review=%d unmatched=%d",
+		format, res.Total, res.Completed, res.Review, res.Unmatched)
+	return res, nil
+}
+
+// completeTransfer completes the payment like a provider callback would, so
+// the signal goes through the callback outbox. The transaction key becomes
+// the payment's gateway reference, which is how reconciledPayment
+// recognises the transaction in a later import.
+func (svc *implPaymentService) completeTransfer(ctx context.Context, p models.Payment, tx statement.Transaction) error {
+	err := svc.applyCallback(ctx, transferEvent(p, tx))
+	if err != nil && !errors.Is(err, ErrDuplicateCallback) {
+		svc.l.Errorf(ctx, "failed to complete payment %s: %v", p.ID.Hex(), err)
+	}
+	return err
+}
+
+// reconciledPayment returns the payment an earlier import matched tx to,
+// or an empty payment for a transaction seen for the first time.
+func (svc *implPaymentService) reconciledPayment(ctx context.Context, tx statement.Transaction) (models.Payment, error) {
+	p, err := svc.repo.FindByGatewayReference(ctx, tx.Key())
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Payment{}, nil
+	}
+	if err != nil {
+		svc.l.Errorf(ctx, "failed to look up transaction %s: %v", tx.Key(), err)
+		return models.Payment{}, err
+	}
+	return p, nil
+}
+
+// requeueTransfer repairs an earlier import that completed p but failed
+// before its signal was queued, and reports whether it had to. Payments
+// already signalled, or no longer completed, are left alone.
+func (svc *implPaymentService) requeueTransfer(ctx context.Context, p models.Payment, tx statement.Transaction) (bool, error) {
+	if p.Status != models.PaymentStatusCompleted {
+		return false, nil
+	}
+
+	err := svc.applyCallback(ctx, transferEvent(p, tx))
+	switch {
+	case errors.Is(err, ErrDuplicateCallback):
+		return false, nil
+	case err != nil:
+		svc.l.Errorf(ctx, "failed to requeue payment %s: %v", p.ID.Hex(), err)
+		return false, err
+	}
+
+	svc.l.Infof(ctx, "Requeued completion of payment %s from transaction %s", p.ID.Hex(), tx.Key())
+	return true, nil
+}
+
+func transferEvent(p models.Payment, tx statement.Transaction) CallbackEvent {
+	return CallbackEvent{
+		OrderCode:        p.OrderCode,
+		Status:           models.PaymentStatusCompleted,
+		GatewayReference: tx.Key(),
+		Amount:           tx.Amount,
+	}
+}
+
+// transferCandidates keeps the payments whose gateway settles by bank
+// transfer. Online gateways once defaulted to bank_transfer, so the stored
+// method alone is not trusted.
+func (svc *implPaymentService) transferCandidates(ps []models.Payment) []models.Payment {
+	out := ps[:0]
+	for _, p := range ps {
+		gw, err := svc.gwf.GetGateway(p.Provider)
+		if err != nil || MethodOf(gw) != models.PaymentMethodBankTransfer {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// matchTransaction looks for the order code in the transfer memo. Only a
+// single exact reference with the exact amount and currency is trusted;
+// everything else that looks related goes to manual review.
+func matchTransaction(tx statement.Transaction, pending []models.Payment) statementMatch {
+	tokens := referenceTokens(tx.Reference + " " + tx.Description)
+
+	var exact, fuzzy []models.Payment
+	for _, p := range pending {
+		code := normalizeReference(p.OrderCode)
+		if code == "" {
+			continue
+		}
+		if hasReference(tokens, code) {
+			exact = append(exact, p)
+			continue
+		}
+		for _, t := range tokens {
+			if len(t) >= len(code)-fuzzyReferenceDistance && levenshtein(t, code) <= fuzzyReferenceDistance {
+				fuzzy = append(fuzzy, p)
+				break
+			}
+		}
+	}
+
+	switch {
+	case len(exact) == 1 && sameAmount(exact[0], tx):
+		return statementMatch{kind: matchExact, payment: exact[0]}
+	case len(exact) == 1:
+		return statementMatch{kind: matchReview, reason: models.ReviewReasonAmountMismatch, candidates: paymentIDs(exact)}
+	case len(exact) > 1:
+		return statementMatch{kind: matchReview, reason: models.ReviewReasonAmbiguous, candidates: paymentIDs(exact)}
+	case len(fuzzy) > 0:
+		return statementMatch{kind: matchReview, reason: models.ReviewReasonFuzzyReference, candidates: paymentIDs(fuzzy)}
+	default:
+		return statementMatch{kind: matchNone}
+	}
+}
+
+// hasReference reports whether code, normalized, is a whole memo token or a
+// run of consecutive tokens, as when the bank replaced its separators. A
+// code is never matched inside a longer token: ORD12 is not ORD123.
+func hasReference(tokens []string, code string) bool {
+	for i := range tokens {
+		run := ""
+		for _, t := range tokens[i:] {
+			run += t
+			if run == code {
+				return true
+			}
+			if len(run) >= len(code) {
+				break
+			}
+		}
+	}
+	return false
+}
+
+func sameAmount(p models.Payment, tx statement.Transaction) bool {
+	return currencyOf(p.Currency) == currencyOf(tx.Currency) && math.Abs(p.Amount-tx.Amount) < amountTolerance
+}
+
+// currencyOf treats a missing currency, e.g. on payments created before
+// currencies were stored, as the default one.
+func currencyOf(c string) string {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(c)
+}
+
+// normalizeReference strips everything but letters and digits, since banks
+// routinely drop or replace separators in transfer memos.
+func normalizeReference(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+func referenceTokens(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = strings.ToUpper(f)
+	}
+	return fields
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func paymentIDs(ps []models.Payment) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(ps))
+	for _, p := range ps {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func removePayment(ps []models.Payment, id primitive.ObjectID) []models.Payment {
+	out := ps[:0]
+	for _, p := range ps {
+		if p.ID != id {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package banktransfer_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+)
+
+const gatewayTypeTransfer models.GatewayType = "transfer"
+
+// transferGateway stands in for a gateway settled by transfers to our own
+// bank account, the only payments statements are matched against.
+type transferGateway struct{}
+
+func (transferGateway) ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest, ord *order.OrderData) (*payment.ProcessPaymentResponse, error) {
+	return &payment.ProcessPaymentResponse{}, nil
+}
+
+func (transferGateway) ParseCallback(ctx context.Context, r *http.Request) (bankTf.CallbackEvent, error) {
+	return bankTf.CallbackEvent{}, bankTf.ErrInvalidCallback
+}
+
+func (transferGateway) AcknowledgeCallback(w http.ResponseWriter, err error) {}
+
+func (transferGateway) PaymentMethod() models.PaymentMethod {
+	return models.PaymentMethodBankTransfer
+}
+
+func newTransferEnv(t *testing.T) *callbackEnv {
+	t.Helper()
+
+	env := newCallbackEnv(t)
+	require.NoError(t, env.gwf.RegisterGateway(gatewayTypeTransfer, transferGateway{}))
+	return env
+}
+
+func (e *callbackEnv) addPayment(t *testing.T, p models.Payment) models.Payment {
+	t.Helper()
+
+	if p.Status == "" {
+		p.Status = models.PaymentStatusPending
+	}
+	p.Method = models.PaymentMethodBankTransfer
+	p.Currency = bankTf.DefaultCurrency
+	p, err := e.payments.Create(context.Background(), p)
+	require.NoError(t, err)
+	return p
+}
+
+// mt940 builds a VND statement with one credit per memo, bank reference
+// REF<n> and the given amount.
+func mt940(amount int, memos ...string) []byte {
+	var b strings.Builder
+	b.WriteString(":20:STMT\n:25:0011223344\n:28C:1/1\n:60F:C240101VND0,\n")
+	for i, memo := range memos {
+		fmt.Fprintf(&b, ":61:2401020102C%d,NTRFNONREF//REF%d\n:86:%s\n", amount, i+1, memo)
+	}
+	b.WriteString(":62F:C240102VND0,\n")
+	return []byte(b.String())
+}
+
+func (e *callbackEnv) importStatement(t *testing.T, content []byte) *payment.ImportBankStatementResponse {
+	t.Helper()
+
+	res, err := e.svc.ImportBankStatement(context.Background(), &payment.ImportBankStatementRequest{
+		Format:  string(models.StatementFormatMT940),
+		Content: content,
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func (e *callbackEnv) expectCompletionSignal(orderCode string) {
+	e.temporal.On("SignalWorkflow", mock.Anything, bankTf.WorkflowPrePaymentPrefix+orderCode, "", bankTf.SignalNamePaymentCompleted,
+		mock.MatchedBy(func(sig bankTf.PaymentSignal) bool {
+			return sig.OrderCode == orderCode && sig.Status == models.PaymentStatusCompleted
+		})).Return(nil).Once()
+}
+
+func TestImportBankStatementMatchesWholeReferences(t *testing.T) {
+	env := newTransferEnv(t)
+	env.addPayment(t, models.Payment{OrderCode: "ORD12", Provider: gatewayTypeTransfer, Amount: 50000})
+	// Stored as a bank transfer before ZaloPay reported its own method.
+	env.addPayment(t, models.Payment{OrderCode: "ZP7", Provider: models.GatewayTypeZalopay, Amount: 50000})
+
+	env.expectCompletionSignal("ORD12")
+
+	res := env.importStatement(t, mt940(50000, "Thanh toan ORD123", "ZP7", "Thanh toan ORD-12"))
+
+	require.Equal(t, int32(1), res.Completed)
+	require.Equal(t, []string{"ORD12"}, res.CompletedOrderCodes)
+	require.Equal(t, int32(1), res.Review)
+	require.Equal(t, int32(1), res.Unmatched)
+
+	// ORD123 is one character off ORD12: worth a look, never a match.
+	reviews := env.reviews.all()
+	require.Len(t, reviews, 1)
+	require.Equal(t, "REF1", reviews[0].TransactionID)
+	require.Equal(t, models.ReviewReasonFuzzyReference, reviews[0].Reason)
+
+	p := env.payment(t, "ORD12")
+	require.Equal(t, models.PaymentStatusCompleted, p.Status)
+	require.Equal(t, "REF3", p.GatewayReference)
+	require.Equal(t, models.PaymentStatusPending, env.payment(t, "ZP7").Status)
+}
+
+func TestImportBankStatementRejectsOtherCurrency(t *testing.T) {
+	env := newTransferEnv(t)
+	env.addPayment(t, models.Payment{OrderCode: "ORD12", Provider: gatewayTypeTransfer, Amount: 50000})
+
+	usd := strings.ReplaceAll(string(mt940(50000, "ORD12")), "VND", "USD")
+	res := env.importStatement(t, []byte(usd))
+
+	require.Equal(t, int32(0), res.Completed)
+	require.Equal(t, int32(1), res.Review)
+	require.Equal(t, models.ReviewReasonAmountMismatch, env.reviews.all()[0].Reason)
+	require.Equal(t, models.PaymentStatusPending, env.payment(t, "ORD12").Status)
+}
+
+func TestImportBankStatementRequeuesUnsignalledTransfer(t *testing.T) {
+	env := newTransferEnv(t)
+	// An earlier import completed the payment but died before queueing it.
+	env.addPayment(t, models.Payment{
+		OrderCode:        "ORD12",
+		Provider:         gatewayTypeTransfer,
+		Amount:           50000,
+		Status:           models.PaymentStatusCompleted,
+		GatewayReference: "REF1",
+	})
+
+	env.expectCompletionSignal("ORD12")
+	res := env.importStatement(t, mt940(50000, "ORD12"))
+	require.Equal(t, []string{"ORD12"}, res.CompletedOrderCodes)
+	require.Len(t, env.outbox.all(), 1)
+	require.Equal(t, models.OutboxStatusDispatched, env.outbox.all()[0].Status)
+
+	// Once signalled, importing the statement again changes nothing.
+	res = env.importStatement(t, mt940(50000, "ORD12"))
+	require.Equal(t, int32(0), res.Completed)
+	require.Equal(t, int32(0), res.Unmatched)
+}
@@ -0,0 +1,190 @@
+package banktransfer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.temporal.io/sdk/testsuite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const gatewayTypeRefunds models.GatewayType = "refunds"
+
+// refundGateway records the refunds it is asked for, or fails them all
+// with err. Like ZaloPay, it refunds a merchant refund ID once. lostAck
+// fails the next call after the refund went through, as a timeout would.
+type refundGateway struct {
+	mu      sync.Mutex
+	err     error
+	lostAck error
+	calls   int
+	refunds []float64
+	refs    map[string]string
+}
+
+func (g *refundGateway) ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest, ord *order.OrderData) (*payment.ProcessPaymentResponse, error) {
+	return &payment.ProcessPaymentResponse{}, nil
+}
+
+func (g *refundGateway) ParseCallback(ctx context.Context, r *http.Request) (bankTf.CallbackEvent, error) {
+	return bankTf.CallbackEvent{}, bankTf.ErrInvalidCallback
+}
+
+func (g *refundGateway) AcknowledgeCallback(w http.ResponseWriter, err error) {}
+
+func (g *refundGateway) RefundPayment(ctx context.Context, p models.Payment, rf models.PaymentRefund) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls++
+	if g.err != nil {
+		return "", g.err
+	}
+	ref, ok := g.refs[rf.ID]
+	if !ok {
+		g.refunds = append(g.refunds, rf.Amount)
+		ref = fmt.Sprintf("RF%d", len(g.refunds))
+		if g.refs == nil {
+			g.refs = make(map[string]string)
+		}
+		g.refs[rf.ID] = ref
+	}
+	if err := g.lostAck; err != nil {
+		g.lostAck = nil
+		return "", err
+	}
+	return ref, nil
+}
+
+func newRefundEnv(t *testing.T, amount float64) (*callbackEnv, *refundGateway) {
+	t.Helper()
+
+	env := newCallbackEnv(t)
+	gw := &refundGateway{}
+	require.NoError(t, env.gwf.RegisterGateway(gatewayTypeRefunds, gw))
+	_, err := env.payments.Create(context.Background(), models.Payment{
+		OrderCode: "ORD-RF",
+		Provider:  gatewayTypeRefunds,
+		Status:    models.PaymentStatusCompleted,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	return env, gw
+}
+
+func (e *callbackEnv) refund(amount float64) (*payment.RefundPaymentResponse, error) {
+	return e.svc.RefundPayment(context.Background(), &payment.RefundPaymentRequest{OrderCode: "ORD-RF", Amount: amount})
+}
+
+func TestRefundPaymentTracksPartialRefunds(t *testing.T) {
+	env, gw := newRefundEnv(t, 100000)
+
+	res, err := env.refund(30000)
+	require.NoError(t, err)
+	require.Equal(t, "RF1", res.RefundId)
+	require.Equal(t, payment.PaymentStatus_PAYMENT_STATUS_COMPLETED, res.Status)
+
+	_, err = env.refund(80000)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// A zero amount refunds what is left.
+	res, err = env.refund(0)
+	require.NoError(t, err)
+	require.Equal(t, payment.PaymentStatus_PAYMENT_STATUS_REFUNDED, res.Status)
+
+	require.Equal(t, []float64{30000, 70000}, gw.refunds)
+	p := env.payment(t, "ORD-RF")
+	require.Equal(t, 100000.0, p.RefundedAmount)
+	require.Len(t, p.Refunds, 2)
+	require.Equal(t, "RF1", p.Refunds[0].GatewayReference)
+	require.Equal(t, "RF2", p.Refunds[1].GatewayReference)
+}
+
+func TestRefundPaymentHonoursIdempotencyKey(t *testing.T) {
+	env, gw := newRefundEnv(t, 100000)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("idempotency-key", "refund-1"))
+	req := &payment.RefundPaymentRequest{OrderCode: "ORD-RF", Amount: 30000}
+
+	first, err := env.svc.RefundPayment(ctx, req)
+	require.NoError(t, err)
+	again, err := env.svc.RefundPayment(ctx, req)
+	require.NoError(t, err)
+
+	require.Equal(t, first.RefundId, again.RefundId)
+	require.Equal(t, []float64{30000}, gw.refunds)
+	require.Equal(t, 30000.0, env.payment(t, "ORD-RF").RefundedAmount)
+}
+
+func TestRefundPaymentRejectsConcurrentOverRefund(t *testing.T) {
+	env, gw := newRefundEnv(t, 100000)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, 2)
+	)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = env.refund(60000)
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, gw.refunds, 1)
+	require.True(t, (errs[0] == nil) != (errs[1] == nil), "exactly one refund should go through: %v", errs)
+	require.Equal(t, 60000.0, env.payment(t, "ORD-RF").RefundedAmount)
+}
+
+func TestRefundPaymentReleasesFailedRefund(t *testing.T) {
+	env, gw := newRefundEnv(t, 100000)
+
+	gw.err = errors.New("provider rejected refund")
+	_, err := env.refund(100000)
+	require.Error(t, err)
+	p := env.payment(t, "ORD-RF")
+	require.Zero(t, p.RefundedAmount)
+	require.Equal(t, models.PaymentStatusCompleted, p.Status)
+
+	gw.err = nil
+	res, err := env.refund(100000)
+	require.NoError(t, err)
+	require.Equal(t, payment.PaymentStatus_PAYMENT_STATUS_REFUNDED, res.Status)
+}
+
+func TestIssueRefundRetryDoesNotRefundTwice(t *testing.T) {
+	env, gw := newRefundEnv(t, 100000)
+	gw.lostAck = errors.New("refund response timed out")
+
+	acts, err := bankTf.NewPaymentActivities(env.svc, nil)
+	require.NoError(t, err)
+	var s testsuite.WorkflowTestSuite
+	wf := s.NewTestWorkflowEnvironment()
+	bankTf.RegisterPaymentWorker(wf, acts)
+
+	// The provider takes the refund but the first attempt fails anyway; the
+	// retry sends the same refund and gets it back.
+	wf.ExecuteWorkflow(bankTf.WorkflowRefundPayment, bankTf.RefundWorkflowParams{OrderCode: "ORD-RF", Amount: 40000})
+	require.NoError(t, wf.GetWorkflowError())
+	var res *payment.RefundPaymentResponse
+	require.NoError(t, wf.GetWorkflowResult(&res))
+	require.Equal(t, "RF1", res.RefundId)
+
+	require.Equal(t, 2, gw.calls)
+	require.Equal(t, []float64{40000}, gw.refunds)
+	p := env.payment(t, "ORD-RF")
+	require.Equal(t, 40000.0, p.RefundedAmount)
+	require.Len(t, p.Refunds, 1)
+	require.Equal(t, models.PaymentStatusCompleted, p.Refunds[0].Status)
+}
@@ -0,0 +1,84 @@
+package banktransfer_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay/zalopaytest"
+)
+
+func (e *callbackEnv) resolveReturn(t *testing.T, redirectURL string, confirm bool) (bankTf.ReturnResult, error) {
+	t.Helper()
+
+	r := httptest.NewRequest("GET", redirectURL, nil)
+	return bankTf.ResolvePaymentReturn(context.Background(), e.svc, models.GatewayTypeZalopay, r, confirm)
+}
+
+func TestResolveReturnConfirmsPaymentWhoseCallbackIsLate(t *testing.T) {
+	env := newCallbackEnv(t)
+	appTransID := env.createPayment(t, "ORD-RET-1", 50000)
+
+	// The callback never gets through, so only the redirect knows.
+	env.zalopay.CallbackURL = "http://127.0.0.1:1"
+	env.zalopay.CallbackAttempts = 1
+	require.ErrorIs(t, env.zalopay.Pay(appTransID), zalopaytest.ErrNotAcknowledged)
+
+	redirectURL, err := env.zalopay.RedirectURL(appTransID)
+	require.NoError(t, err)
+	require.Contains(t, redirectURL, "bookingCode=ORD-RET-1")
+
+	res, err := env.resolveReturn(t, redirectURL, false)
+	require.NoError(t, err)
+	require.Equal(t, bankTf.ReturnResult{OrderCode: "ORD-RET-1", Status: models.PaymentStatusCompleted}, res)
+
+	res, err = env.resolveReturn(t, redirectURL, true)
+	require.NoError(t, err)
+	require.Equal(t, bankTf.ReturnResult{OrderCode: "ORD-RET-1", Status: models.PaymentStatusCompleted, Confirmed: true}, res)
+
+	// The landing page never settles the payment itself.
+	require.Equal(t, models.PaymentStatusPending, env.payment(t, "ORD-RET-1").Status)
+}
+
+func TestResolveReturnPrefersProviderStatusOverRedirect(t *testing.T) {
+	env := newCallbackEnv(t)
+	appTransID := env.createPayment(t, "ORD-RET-2", 50000)
+
+	// Not paid yet: ZaloPay's query still reports the order as processing.
+	redirectURL, err := env.zalopay.RedirectURL(appTransID)
+	require.NoError(t, err)
+
+	res, err := env.resolveReturn(t, redirectURL, false)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusFailed, res.Status)
+	require.False(t, res.Confirmed)
+
+	res, err = env.resolveReturn(t, redirectURL, true)
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusPending, res.Status)
+	require.True(t, res.Confirmed)
+}
+
+func TestResolveReturnRejectsTamperedRedirect(t *testing.T) {
+	env := newCallbackEnv(t)
+	appTransID := env.createPayment(t, "ORD-RET-3", 50000)
+	redirectURL, err := env.zalopay.RedirectURL(appTransID)
+	require.NoError(t, err)
+
+	for name, tampered := range map[string]string{
+		"status":      strings.Replace(redirectURL, "status=-49", "status=1", 1),
+		"amount":      strings.Replace(redirectURL, "amount=50000", "amount=1", 1),
+		"bookingCode": strings.Replace(redirectURL, "bookingCode=ORD-RET-3", "bookingCode=ORD-OTHER", 1),
+		"checksum":    strings.Replace(redirectURL, "checksum=", "checksum=0", 1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NotEqual(t, redirectURL, tampered)
+			_, err := env.resolveReturn(t, tampered, true)
+			require.ErrorIs(t, err, bankTf.ErrInvalidCallback)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package banktransfer
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+)
+
+// HealthChecker reports whether a provider is currently healthy. Unhealthy
+// providers are still routed to, but only after every healthy one.
+type HealthChecker interface {
+	Healthy(gatewayType models.GatewayType) bool
+}
+
+type RouteRequest struct {
+	Provider models.GatewayType
+	Amount   float64
+	Currency string
+	Segment  string
+}
+
+type GatewayRouter struct {
+	gwf    *GatewayFactory
+	rules  []config.RoutingRule
+	health HealthChecker
+}
+
+func NewGatewayRouter(gwf *GatewayFactory, rules []config.RoutingRule, health HealthChecker) *GatewayRouter {
+	return &GatewayRouter{
+		gwf:    gwf,
+		rules:  rules,
+		health: health,
+	}
+}
+
+// Route returns the gateways to try, in order. An explicit provider is
+// honoured as the only candidate; otherwise eligible gateways are ordered by
+// health and then by a weighted random draw.
+func (r *GatewayRouter) Route(req RouteRequest) ([]models.GatewayType, error) {
+	if req.Provider != "" {
+		if _, err := r.gwf.GetGateway(req.Provider); err != nil {
+			return nil, err
+		}
+		return []models.GatewayType{req.Provider}, nil
+	}
+
+	var healthy, unhealthy []config.RoutingRule
+	seen := make(map[models.GatewayType]bool)
+	for _, rule := range r.rules {
+		gt := models.GatewayType(rule.Provider)
+		if seen[gt] || !ruleMatches(rule, req) {
+			continue
+		}
+		if _, err := r.gwf.GetGateway(gt); err != nil {
+			continue
+		}
+		seen[gt] = true
+
+		if r.health != nil && !r.health.Healthy(gt) {
+			unhealthy = append(unhealthy, rule)
+		} else {
+			healthy = append(healthy, rule)
+		}
+	}
+
+	if len(healthy) == 0 && len(unhealthy) == 0 {
+		return nil, ErrNoEligibleGateway
+	}
+
+	return append(weightedOrder(healthy), weightedOrder(unhealthy)...), nil
+}
+
+func ruleMatches(rule config.RoutingRule, req RouteRequest) bool {
+	if req.Amount < rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount > 0 && req.Amount > rule.MaxAmount {
+		return false
+	}
+	if len(rule.Currencies) > 0 && !slices.ContainsFunc(rule.Currencies, func(c string) bool {
+		return strings.EqualFold(c, req.Currency)
+	}) {
+		return false
+	}
+	if len(rule.Segments) > 0 && !slices.Contains(rule.Segments, req.Segment) {
+		return false
+	}
+	return true
+}
+
+// weightedOrder draws rules without replacement, each draw proportional to
+// the remaining weights. Rules without a weight count as 1.
+func weightedOrder(rules []config.RoutingRule) []models.GatewayType {
+	rules = slices.Clone(rules)
+	out := make([]models.GatewayType, 0, len(rules))
+
+	for len(rules) > 0 {
+		total := 0
+		for _, rule := range rules {
+			total += max(rule.Weight, 1)
+		}
+
+		pick := rand.IntN(total)
+		for i, rule := range rules {
+			pick -= max(rule.Weight, 1)
+			if pick < 0 {
+				out = append(out, models.GatewayType(rule.Provider))
+				rules = slices.Delete(rules, i, i+1)
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+// IsRetryable reports whether a gateway error is worth failing over on: the
+// provider could not be reached or answered with a server error, as opposed
+// to rejecting the payment.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrGatewayUnavailable) || errors.Is(err, gatewayhttp.ErrUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
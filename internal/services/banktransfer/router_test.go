@@ -0,0 +1,26 @@
+package banktransfer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/cod"
+)
+
+func TestRouteMatchesCurrencyIgnoringCase(t *testing.T) {
+	gwf := bankTf.NewPaymentGatewayFactory()
+	require.NoError(t, gwf.RegisterGateway(models.GatewayTypeCOD, cod.New()))
+	router := bankTf.NewGatewayRouter(gwf, []config.RoutingRule{
+		{Provider: string(models.GatewayTypeCOD), Currencies: []string{"VND"}},
+	}, nil)
+
+	gateways, err := router.Route(bankTf.RouteRequest{Amount: 50000, Currency: "vnd"})
+	require.NoError(t, err)
+	require.Equal(t, []models.GatewayType{models.GatewayTypeCOD}, gateways)
+
+	_, err = router.Route(bankTf.RouteRequest{Amount: 50000, Currency: "usd"})
+	require.ErrorIs(t, err, bankTf.ErrNoEligibleGateway)
+}
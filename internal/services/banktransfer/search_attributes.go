@@ -0,0 +1,82 @@
+package banktransfer
+
+import (
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Search attributes set on the workflows the service starts, so payments can
+// be found with visibility queries such as
+// `PaymentStatus = "pending" AND ExecutionStatus = "Running"`. They must be
+// registered in the namespace first, see scripts/register-search-attributes.sh.
+var (
+	SearchAttrOrderCode     = temporal.NewSearchAttributeKeyKeyword("OrderCode")
+	SearchAttrPaymentID     = temporal.NewSearchAttributeKeyKeyword("PaymentID")
+	SearchAttrProvider      = temporal.NewSearchAttributeKeyKeyword("Provider")
+	SearchAttrAmount        = temporal.NewSearchAttributeKeyFloat64("Amount")
+	SearchAttrPaymentStatus = temporal.NewSearchAttributeKeyKeyword("PaymentStatus")
+)
+
+// searchAttributes is empty unless TEMPORAL_SEARCH_ATTRIBUTES is on, as
+// starting a workflow with unregistered attributes fails.
+func (svc *implPaymentService) searchAttributes(sig PaymentSignal) temporal.SearchAttributes {
+	if !svc.wfCfg.SearchAttributes {
+		return temporal.SearchAttributes{}
+	}
+
+	updates := []temporal.SearchAttributeUpdate{
+		SearchAttrOrderCode.ValueSet(sig.OrderCode),
+		SearchAttrPaymentStatus.ValueSet(string(sig.Status)),
+	}
+	if sig.PaymentID != "" {
+		updates = append(updates, SearchAttrPaymentID.ValueSet(sig.PaymentID))
+	}
+	if sig.Provider != "" {
+		updates = append(updates, SearchAttrProvider.ValueSet(sig.Provider))
+	}
+	if sig.Amount > 0 {
+		updates = append(updates, SearchAttrAmount.ValueSet(sig.Amount))
+	}
+	return temporal.NewSearchAttributes(updates...)
+}
+
+// upsertPaymentStatus updates PaymentStatus on workflows started with search
+// attributes; others are left alone, as their namespace may lack them.
+func upsertPaymentStatus(ctx workflow.Context, st models.PaymentStatus) {
+	if _, ok := workflow.GetTypedSearchAttributes(ctx).GetKeyword(SearchAttrOrderCode); !ok {
+		return
+	}
+	if err := workflow.UpsertTypedSearchAttributes(ctx, SearchAttrPaymentStatus.ValueSet(string(st))); err != nil {
+		workflow.GetLogger(ctx).Warn("failed to upsert payment status", "Error", err)
+	}
+}
+
+// trackPaymentState answers QueryPaymentState with state for the rest of
+// the workflow.
+func trackPaymentState(ctx workflow.Context, state *PaymentState) error {
+	state.UpdatedAt = workflow.Now(ctx)
+	return workflow.SetQueryHandler(ctx, QueryPaymentState, func() (PaymentState, error) {
+		return *state, nil
+	})
+}
+
+func (s *PaymentState) step(ctx workflow.Context, step string) {
+	s.Step = step
+	s.UpdatedAt = workflow.Now(ctx)
+}
+
+func (s *PaymentState) failed(ctx workflow.Context, err error) {
+	s.LastError = err.Error()
+	s.UpdatedAt = workflow.Now(ctx)
+}
+
+// settle records the payment's new status, on the search attributes too.
+func (s *PaymentState) settle(ctx workflow.Context, st models.PaymentStatus) {
+	if st == "" || st == s.Status {
+		return
+	}
+	s.Status = st
+	s.UpdatedAt = workflow.Now(ctx)
+	upsertPaymentStatus(ctx, st)
+}
@@ -0,0 +1,122 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Element names are matched without namespace so camt.053.001.02 through .08
+// are all accepted.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	NtryRef     string       `xml:"NtryRef"`
+	AcctSvcrRef string       `xml:"AcctSvcrRef"`
+	Amount      camtAmount   `xml:"Amt"`
+	CdtDbtInd   string       `xml:"CdtDbtInd"`
+	BookingDate camtDate     `xml:"BookgDt"`
+	ValueDate   camtDate     `xml:"ValDt"`
+	Details     []camtTxDtls `xml:"NtryDtls>TxDtls"`
+	AddtlInf    string       `xml:"AddtlNtryInf"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Dt   string `xml:"Dt"`
+	DtTm string `xml:"DtTm"`
+}
+
+type camtTxDtls struct {
+	AcctSvcrRef string   `xml:"Refs>AcctSvcrRef"`
+	EndToEndID  string   `xml:"Refs>EndToEndId"`
+	Debtor      string   `xml:"RltdPties>Dbtr>Nm"`
+	Creditor    string   `xml:"RltdPties>Cdtr>Nm"`
+	Ustrd       []string `xml:"RmtInf>Ustrd"`
+	Strd        []string `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+}
+
+type camt053Parser struct{}
+
+func (p *camt053Parser) Parse(r io.Reader) ([]Transaction, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	var txs []Transaction
+	for _, stmt := range doc.Statements {
+		for _, e := range stmt.Entries {
+			amount, err := strconv.ParseFloat(strings.TrimSpace(e.Amount.Value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid amount %q", ErrMalformed, e.Amount.Value)
+			}
+
+			date, err := e.BookingDate.time()
+			if err != nil {
+				if date, err = e.ValueDate.time(); err != nil {
+					return nil, fmt.Errorf("%w: invalid booking date", ErrMalformed)
+				}
+			}
+
+			tx := Transaction{
+				ID:          firstNonEmpty(e.AcctSvcrRef, e.NtryRef),
+				BookingDate: date,
+				Amount:      amount,
+				Currency:    e.Amount.Currency,
+				Credit:      e.CdtDbtInd == "CRDT",
+				Description: e.AddtlInf,
+			}
+
+			if len(e.Details) > 0 {
+				d := e.Details[0]
+				tx.ID = firstNonEmpty(tx.ID, d.AcctSvcrRef, d.EndToEndID)
+				tx.Reference = firstNonEmpty(strings.Join(d.Strd, " "), d.EndToEndID)
+				if ustrd := strings.Join(d.Ustrd, " "); ustrd != "" {
+					tx.Description = ustrd
+				}
+				if tx.Credit {
+					tx.Counterparty = d.Debtor
+				} else {
+					tx.Counterparty = d.Creditor
+				}
+			}
+
+			txs = append(txs, tx)
+		}
+	}
+
+	return txs, nil
+}
+
+func (d camtDate) time() (time.Time, error) {
+	if d.Dt != "" {
+		return time.Parse("2006-01-02", d.Dt)
+	}
+	if t, err := time.Parse(time.RFC3339, d.DtTm); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05", d.DtTm)
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" && v != "NOTPROVIDED" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type csvParser struct {
+	layout CSVLayout
+}
+
+func (l CSVLayout) validate() error {
+	if l.DateColumn < 0 || l.DateLayout == "" {
+		return fmt.Errorf("%w: date column and layout are required", ErrInvalidLayout)
+	}
+	if l.AmountColumn < 0 && l.CreditColumn < 0 {
+		return fmt.Errorf("%w: amount or credit column is required", ErrInvalidLayout)
+	}
+	if l.ReferenceColumn < 0 && l.DescriptionColumn < 0 {
+		return fmt.Errorf("%w: reference or description column is required", ErrInvalidLayout)
+	}
+	return nil
+}
+
+func (p *csvParser) Parse(r io.Reader) ([]Transaction, error) {
+	cr := csv.NewReader(r)
+	if p.layout.Delimiter != 0 {
+		cr.Comma = p.layout.Delimiter
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if len(rows) <= p.layout.SkipRows {
+		return nil, nil
+	}
+
+	var txs []Transaction
+	for i, row := range rows[p.layout.SkipRows:] {
+		line := i + p.layout.SkipRows + 1
+		if isBlankRow(row) {
+			continue
+		}
+
+		date, err := time.Parse(p.layout.DateLayout, p.column(row, p.layout.DateColumn))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid date", ErrMalformed, line)
+		}
+
+		amount, credit, err := p.amount(row)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrMalformed, line, err)
+		}
+
+		txs = append(txs, Transaction{
+			ID:           p.column(row, p.layout.IDColumn),
+			BookingDate:  date,
+			Amount:       amount,
+			Currency:     p.layout.Currency,
+			Credit:       credit,
+			Reference:    p.column(row, p.layout.ReferenceColumn),
+			Description:  p.column(row, p.layout.DescriptionColumn),
+			Counterparty: p.column(row, p.layout.CounterpartyColumn),
+		})
+	}
+
+	return txs, nil
+}
+
+// amount reads either a single signed amount column or the separate
+// credit/debit columns most Vietnamese bank exports use.
+func (p *csvParser) amount(row []string) (float64, bool, error) {
+	if p.layout.AmountColumn >= 0 {
+		v, err := p.number(p.column(row, p.layout.AmountColumn))
+		if err != nil {
+			return 0, false, err
+		}
+		if v < 0 {
+			return -v, false, nil
+		}
+		return v, true, nil
+	}
+
+	if c := p.column(row, p.layout.CreditColumn); c != "" {
+		v, err := p.number(c)
+		if err != nil {
+			return 0, false, err
+		}
+		if v != 0 {
+			return v, true, nil
+		}
+	}
+
+	v, err := p.number(p.column(row, p.layout.DebitColumn))
+	if err != nil {
+		return 0, false, err
+	}
+	return v, false, nil
+}
+
+func (p *csvParser) number(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if p.layout.ThousandsSeparator != "" {
+		s = strings.ReplaceAll(s, p.layout.ThousandsSeparator, "")
+	}
+	if p.layout.DecimalSeparator != "" && p.layout.DecimalSeparator != "." {
+		s = strings.Replace(s, p.layout.DecimalSeparator, ".", 1)
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
+
+func (p *csvParser) column(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+func isBlankRow(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
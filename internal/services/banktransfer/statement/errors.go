@@ -0,0 +1,9 @@
+package statement
+
+import "errors"
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported statement format")
+	ErrMalformed         = errors.New("malformed statement")
+	ErrInvalidLayout     = errors.New("invalid csv layout")
+)
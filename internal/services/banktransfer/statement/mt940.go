@@ -0,0 +1,142 @@
+package statement
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// :61: value date (YYMMDD), optional entry date (MMDD), debit/credit mark,
+// optional funds code, amount, transaction type, customer reference and
+// optional bank reference after "//".
+var mt940Line61 = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?([\d,]+)([A-Z][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+var mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+
+type mt940Parser struct{}
+
+func (p *mt940Parser) Parse(r io.Reader) ([]Transaction, error) {
+	var (
+		txs      []Transaction
+		account  string
+		currency string
+		cur      *Transaction
+		tag      string
+		info     []string
+		n        int
+	)
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		if len(info) > 0 {
+			cur.Description = strings.Join(info, " ")
+		}
+		txs = append(txs, *cur)
+		cur, info = nil, nil
+	}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		n++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" || line == "-" || strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		m := mt940Tag.FindStringSubmatch(line)
+		if m == nil {
+			// Continuation of the previous field.
+			switch tag {
+			case "61", "86":
+				if cur != nil {
+					info = append(info, strings.TrimSpace(line))
+				}
+			}
+			continue
+		}
+
+		tag = m[1]
+		value := strings.TrimSpace(m[2])
+		switch tag {
+		case "25":
+			account = value
+		case "60F", "60M":
+			if len(value) >= 10 {
+				currency = value[7:10]
+			}
+		case "61":
+			flush()
+			tx, err := parseMT940Line61(value)
+			if err != nil {
+				return nil, err
+			}
+			if tx.ID == "" {
+				tx.ID = mt940LineID(account, n, value)
+			}
+			tx.Currency = currency
+			cur = &tx
+		case "86":
+			if cur != nil {
+				info = append(info, value)
+			}
+		case "62F", "62M":
+			flush()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	flush()
+
+	return txs, nil
+}
+
+func parseMT940Line61(v string) (Transaction, error) {
+	m := mt940Line61.FindStringSubmatch(v)
+	if m == nil {
+		return Transaction{}, fmt.Errorf("%w: invalid :61: line %q", ErrMalformed, v)
+	}
+
+	date, err := time.Parse("060102", m[1])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("%w: invalid value date %q", ErrMalformed, m[1])
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(m[5], ",", ".", 1), 64)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("%w: invalid amount %q", ErrMalformed, m[5])
+	}
+
+	// RC/RD are reversals, so they move money in the opposite direction.
+	credit := m[3] == "C" || m[3] == "RD"
+
+	ref := strings.TrimSpace(m[7])
+	if ref == "NONREF" {
+		ref = ""
+	}
+
+	return Transaction{
+		ID:          firstNonEmpty(m[8]),
+		BookingDate: date,
+		Amount:      amount,
+		Credit:      credit,
+		Reference:   ref,
+	}, nil
+}
+
+// mt940LineID identifies an entry without a bank reference by the account,
+// the :61: line, which holds its date and amount, and where that line is.
+// The customer reference cannot: it usually is the memo the payer typed, so
+// two transfers for one order would share it.
+func mt940LineID(account string, line int, v string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%s", account, line, v)))
+	return hex.EncodeToString(sum[:])
+}
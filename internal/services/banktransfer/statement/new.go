@@ -0,0 +1,53 @@
+package statement
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+)
+
+type Parser interface {
+	Parse(r io.Reader) ([]Transaction, error)
+}
+
+func NewParser(format models.StatementFormat, layout CSVLayout) (Parser, error) {
+	switch format {
+	case models.StatementFormatCAMT053:
+		return &camt053Parser{}, nil
+	case models.StatementFormatMT940:
+		return &mt940Parser{}, nil
+	case models.StatementFormatCSV:
+		if err := layout.validate(); err != nil {
+			return nil, err
+		}
+		return &csvParser{layout: layout}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func NewCSVLayout(cfg config.StatementConfig) CSVLayout {
+	delim, _ := utf8.DecodeRuneInString(cfg.CSVDelimiter)
+	if cfg.CSVDelimiter == `\t` {
+		delim = '\t'
+	}
+
+	return CSVLayout{
+		Delimiter:          delim,
+		SkipRows:           cfg.CSVSkipRows,
+		DateColumn:         cfg.CSVDateColumn,
+		DateLayout:         cfg.CSVDateLayout,
+		IDColumn:           cfg.CSVIDColumn,
+		AmountColumn:       cfg.CSVAmountColumn,
+		CreditColumn:       cfg.CSVCreditColumn,
+		DebitColumn:        cfg.CSVDebitColumn,
+		ReferenceColumn:    cfg.CSVReferenceColumn,
+		DescriptionColumn:  cfg.CSVDescriptionColumn,
+		CounterpartyColumn: cfg.CSVCounterpartyColumn,
+		Currency:           cfg.CSVCurrency,
+		DecimalSeparator:   cfg.CSVDecimalSeparator,
+		ThousandsSeparator: cfg.CSVThousandsSeparator,
+	}
+}
@@ -0,0 +1,184 @@
+package statement_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/statement"
+)
+
+func parseFile(t *testing.T, format models.StatementFormat, layout statement.CSVLayout, path string) []statement.Transaction {
+	t.Helper()
+
+	p, err := statement.NewParser(format, layout)
+	require.NoError(t, err)
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	txs, err := p.Parse(f)
+	require.NoError(t, err)
+	return txs
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseCAMT053(t *testing.T) {
+	txs := parseFile(t, models.StatementFormatCAMT053, statement.CSVLayout{}, "testdata/camt053.xml")
+	require.Len(t, txs, 3)
+
+	require.Equal(t, statement.Transaction{
+		ID:           "FT24002001",
+		BookingDate:  day(2),
+		Amount:       50000,
+		Currency:     "VND",
+		Credit:       true,
+		Description:  "Thanh toan don hang ORD12",
+		Counterparty: "NGUYEN VAN A",
+	}, txs[0])
+
+	// A debit names the creditor, and its booking time keeps its offset.
+	require.False(t, txs[1].Credit)
+	require.Equal(t, 15000.50, txs[1].Amount)
+	require.Equal(t, "FEE-0102", txs[1].Reference)
+	require.Equal(t, "Phi dich vu", txs[1].Description)
+	require.Equal(t, "VIETCOMBANK", txs[1].Counterparty)
+	require.True(t, txs[1].BookingDate.Equal(time.Date(2024, 1, 2, 3, 15, 0, 0, time.UTC)))
+
+	// Each entry carries its own currency; without a booking date the value
+	// date is used.
+	require.Equal(t, "USD", txs[2].Currency)
+	require.True(t, txs[2].Credit)
+	require.Equal(t, "3", txs[2].ID)
+	require.Equal(t, "ORD13", txs[2].Reference)
+	require.Equal(t, day(3), txs[2].BookingDate)
+}
+
+func TestParseMT940(t *testing.T) {
+	txs := parseFile(t, models.StatementFormatMT940, statement.CSVLayout{}, "testdata/mt940.sta")
+	require.Len(t, txs, 6)
+
+	require.Equal(t, statement.Transaction{
+		ID:          "FT24002001",
+		BookingDate: day(2),
+		Amount:      50000,
+		Currency:    "VND",
+		Credit:      true,
+		Reference:   "ORD12",
+		Description: "THANH TOAN DON HANG ORD12 NGUYEN VAN A",
+	}, txs[0])
+
+	require.False(t, txs[1].Credit)
+	require.Equal(t, 15000.50, txs[1].Amount)
+	require.Empty(t, txs[1].Reference, "NONREF is no reference")
+
+	// A reversed debit puts money back, a reversed credit takes it out.
+	require.True(t, txs[2].Credit)
+	require.Equal(t, "FT24002003", txs[2].ID)
+	require.False(t, txs[3].Credit)
+	require.Equal(t, "FT24002004", txs[3].ID)
+
+	// Two transfers with the same memo and no bank reference stay apart.
+	require.Equal(t, "ORD13", txs[4].Reference)
+	require.Equal(t, "ORD13", txs[5].Reference)
+	require.NotEqual(t, "ORD13", txs[4].ID)
+	require.NotEqual(t, txs[4].Key(), txs[5].Key())
+
+	// Re-importing the statement gives the same IDs.
+	again := parseFile(t, models.StatementFormatMT940, statement.CSVLayout{}, "testdata/mt940.sta")
+	require.Equal(t, txs[4].Key(), again[4].Key())
+}
+
+func TestParseMT940RejectsMalformedLine(t *testing.T) {
+	p, err := statement.NewParser(models.StatementFormatMT940, statement.CSVLayout{})
+	require.NoError(t, err)
+
+	_, err = p.Parse(strings.NewReader(":20:STMT\n:25:0011223344\n:61:2401XXC50000,NTRFORD12\n"))
+	require.ErrorIs(t, err, statement.ErrMalformed)
+}
+
+func TestParseCSVSplitColumns(t *testing.T) {
+	layout := statement.NewCSVLayout(config.StatementConfig{
+		CSVDelimiter:          ";",
+		CSVSkipRows:           1,
+		CSVDateColumn:         0,
+		CSVDateLayout:         "02/01/2006",
+		CSVIDColumn:           1,
+		CSVAmountColumn:       -1,
+		CSVCreditColumn:       3,
+		CSVDebitColumn:        2,
+		CSVReferenceColumn:    -1,
+		CSVDescriptionColumn:  4,
+		CSVCounterpartyColumn: -1,
+		CSVCurrency:           "VND",
+		CSVDecimalSeparator:   ",",
+		CSVThousandsSeparator: ".",
+	})
+	txs := parseFile(t, models.StatementFormatCSV, layout, "testdata/split_columns.csv")
+	require.Len(t, txs, 3, "blank rows are skipped")
+
+	require.Equal(t, statement.Transaction{
+		ID:          "FT24002001",
+		BookingDate: day(2),
+		Amount:      50000,
+		Currency:    "VND",
+		Credit:      true,
+		Description: "Thanh toan ORD12",
+	}, txs[0])
+
+	require.False(t, txs[1].Credit)
+	require.Equal(t, 15000.50, txs[1].Amount)
+
+	// A zero in the debit column does not hide the credit.
+	require.True(t, txs[2].Credit)
+	require.Equal(t, 1250000.0, txs[2].Amount)
+	require.Equal(t, day(3), txs[2].BookingDate)
+}
+
+func TestParseCSVSignedAmount(t *testing.T) {
+	layout := statement.NewCSVLayout(config.StatementConfig{
+		CSVDelimiter:          `\t`,
+		CSVSkipRows:           1,
+		CSVDateColumn:         0,
+		CSVDateLayout:         "2006-01-02",
+		CSVIDColumn:           -1,
+		CSVAmountColumn:       2,
+		CSVCreditColumn:       -1,
+		CSVDebitColumn:        -1,
+		CSVReferenceColumn:    1,
+		CSVDescriptionColumn:  3,
+		CSVCounterpartyColumn: -1,
+		CSVCurrency:           "VND",
+		CSVDecimalSeparator:   ".",
+		CSVThousandsSeparator: ",",
+	})
+	txs := parseFile(t, models.StatementFormatCSV, layout, "testdata/signed_amount.tsv")
+	require.Len(t, txs, 2)
+
+	require.True(t, txs[0].Credit)
+	require.Equal(t, 50000.0, txs[0].Amount)
+	require.Equal(t, "FT24002001", txs[0].Reference)
+	require.Equal(t, "ORD12", txs[0].Description)
+
+	require.False(t, txs[1].Credit)
+	require.Equal(t, 15000.50, txs[1].Amount)
+}
+
+func TestNewParserRejectsIncompleteCSVLayout(t *testing.T) {
+	_, err := statement.NewParser(models.StatementFormatCSV, statement.CSVLayout{
+		DateColumn:        0,
+		DateLayout:        "02/01/2006",
+		AmountColumn:      -1,
+		CreditColumn:      -1,
+		ReferenceColumn:   -1,
+		DescriptionColumn: 1,
+	})
+	require.ErrorIs(t, err, statement.ErrInvalidLayout)
+}
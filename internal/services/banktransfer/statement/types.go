@@ -0,0 +1,51 @@
+package statement
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Transaction is a single statement line normalized across formats.
+// Amount is always positive; Credit tells the direction.
+type Transaction struct {
+	ID           string
+	BookingDate  time.Time
+	Amount       float64
+	Currency     string
+	Credit       bool
+	Reference    string
+	Description  string
+	Counterparty string
+}
+
+// Key identifies the transaction across imports. Statements without a bank
+// reference fall back to a hash of the line contents.
+func (t Transaction) Key() string {
+	if t.ID != "" {
+		return t.ID
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%.2f|%t|%s|%s",
+		t.BookingDate.Format("2006-01-02"), t.Amount, t.Credit, t.Reference, t.Description)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CSVLayout describes where each field lives in a bank's CSV export.
+// Column indexes are zero-based; a negative index means the column is absent.
+type CSVLayout struct {
+	Delimiter          rune
+	SkipRows           int
+	DateColumn         int
+	DateLayout         string
+	IDColumn           int
+	AmountColumn       int
+	CreditColumn       int
+	DebitColumn        int
+	ReferenceColumn    int
+	DescriptionColumn  int
+	CounterpartyColumn int
+	Currency           string
+	DecimalSeparator   string
+	ThousandsSeparator string
+}
@@ -1,5 +1,194 @@
 package banktransfer
 
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+)
+
 type OrderWorkflowParams struct {
 	OrderCode string
 }
+
+// PaymentSignal is the payload of the payment-completed and payment-failed
+// signals. PaymentID is empty for orders whose payment was never persisted.
+// A provider repeating its callback repeats the signal, so receivers should
+// act on the first one only.
+type PaymentSignal struct {
+	PaymentID        string
+	OrderCode        string
+	Provider         string
+	Amount           float64
+	GatewayReference string
+	Status           models.PaymentStatus
+	Reason           string
+}
+
+type PaymentWorkflowParams struct {
+	OrderCode string
+}
+
+// SettlePaymentParams is a final status a gateway reported for the payment
+// of an order.
+type SettlePaymentParams struct {
+	OrderCode string
+	Status    models.PaymentStatus
+}
+
+// RefundWorkflowParams refunds the payment of an order. A zero Amount
+// refunds whatever has not been refunded yet.
+type RefundWorkflowParams struct {
+	OrderCode string
+	Amount    float64
+	Reason    string
+}
+
+// PollPaymentParams polls one payment until ExpiresAt.
+type PollPaymentParams struct {
+	PaymentID       string
+	OrderCode       string
+	Provider        string
+	Amount          float64
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	ExpiresAt       time.Time
+}
+
+// PollResult is Done once the payment settled or a newer payment replaced
+// it, after which polling stops.
+type PollResult struct {
+	Status models.PaymentStatus
+	Done   bool
+}
+
+// OrderStatusParams sends IdempotencyKey, when set, as gRPC metadata.
+type OrderStatusParams struct {
+	OrderCode      string
+	Status         order.OrderStatus
+	IdempotencyKey string
+}
+
+// CompensationParams undoes an order's reservation after its payment failed
+// for good.
+type CompensationParams struct {
+	OrderCode string
+	PaymentID string
+	Provider  string
+	Amount    float64
+	Reason    string
+}
+
+// PaymentState is what a payment workflow answers to QueryPaymentState.
+// Step is the work in progress, or "done" once the workflow finished;
+// LastError is the latest failed attempt.
+type PaymentState struct {
+	OrderCode   string
+	PaymentID   string
+	Provider    string
+	Amount      float64
+	Status      models.PaymentStatus
+	Step        string
+	Checks      int
+	NextCheckAt time.Time
+	LastError   string
+	UpdatedAt   time.Time
+}
+
+type CompensationOrder struct {
+	Status order.OrderStatus
+	Items  []InventoryItem
+}
+
+type InventoryItem struct {
+	ProductID string
+	Quantity  int32
+}
+
+type ReleaseInventoryParams struct {
+	OrderCode      string
+	Items          []InventoryItem
+	IdempotencyKey string
+}
+
+// CompensationResult lists the steps done and those still failing after
+// their retries. Skipped explains why nothing was compensated.
+type CompensationResult struct {
+	OrderCode string
+	PaymentID string
+	Completed []string
+	Failed    []string
+	Skipped   string
+}
+
+// CallbackEvent is a verified provider callback. Status is either
+// PaymentStatusCompleted or PaymentStatusFailed; Reason explains a failure.
+// KeyVersion names the key that verified the signature, if the gateway
+// rotates keys.
+type CallbackEvent struct {
+	OrderCode        string
+	Status           models.PaymentStatus
+	GatewayReference string
+	Amount           float64
+	Reason           string
+	KeyVersion       string
+}
+
+// RedirectEvent is a verified return-URL redirect: the query string a
+// provider signs when it sends the customer back. Status is what the
+// provider claimed at that moment, so pending payments may still settle.
+type RedirectEvent struct {
+	OrderCode        string
+	Status           models.PaymentStatus
+	GatewayReference string
+	Amount           float64
+}
+
+// ReturnResult is where the customer landing on the return URL stands.
+// Confirmed is set when Status came from the payment record or the
+// provider's status API rather than the redirect alone.
+type ReturnResult struct {
+	OrderCode string
+	Status    models.PaymentStatus
+	Confirmed bool
+}
+
+// Bank is a payment channel offered by a provider. Zero amounts mean the
+// provider sets no limit.
+type Bank struct {
+	Code         string
+	Name         string
+	Channel      string
+	MinAmount    float64
+	MaxAmount    float64
+	DisplayOrder int
+}
+
+// Accepts reports whether the bank takes a payment of amount.
+func (b Bank) Accepts(amount float64) bool {
+	if b.MinAmount > 0 && amount < b.MinAmount {
+		return false
+	}
+	return b.MaxAmount == 0 || amount <= b.MaxAmount
+}
+
+// WriteJSONAck is the acknowledgement for providers without a prescribed
+// response format: 200 when the callback was handled or is a duplicate, 400
+// when it was rejected and 500 when the provider should retry.
+func WriteJSONAck(w http.ResponseWriter, err error) {
+	code, body := http.StatusOK, map[string]string{"status": "success"}
+	switch {
+	case errors.Is(err, ErrDuplicateCallback):
+	case errors.Is(err, ErrInvalidCallback):
+		code, body = http.StatusBadRequest, map[string]string{"status": "error", "message": err.Error()}
+	case err != nil:
+		code, body = http.StatusInternalServerError, map[string]string{"status": "error", "message": ErrInternal.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
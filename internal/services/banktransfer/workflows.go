@@ -0,0 +1,144 @@
+package banktransfer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+func workflowOptions(name string) workflow.RegisterOptions {
+	return workflow.RegisterOptions{Name: name}
+}
+
+func withActivityOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    5,
+		},
+	})
+}
+
+// SyncPaymentStatusWorkflow asks the gateway for the payment's status and,
+// once it is final, settles the payment through the callback path: the
+// order's workflow is signalled, or compensation started for a failure. A
+// pending payment is returned unchanged.
+func SyncPaymentStatusWorkflow(ctx workflow.Context, params PaymentWorkflowParams) (models.PaymentStatus, error) {
+	ctx = withActivityOptions(ctx)
+
+	state := PaymentState{OrderCode: params.OrderCode, Step: StepCheckingStatus}
+	if err := trackPaymentState(ctx, &state); err != nil {
+		return "", err
+	}
+
+	var st models.PaymentStatus
+	if err := workflow.ExecuteActivity(ctx, ActivityQueryGatewayStatus, params).Get(ctx, &st); err != nil {
+		state.failed(ctx, err)
+		return "", err
+	}
+	state.Status = st
+	if st != models.PaymentStatusCompleted && st != models.PaymentStatusFailed {
+		state.step(ctx, StepDone)
+		return st, nil
+	}
+
+	state.step(ctx, StepRecordingResult)
+	if err := workflow.ExecuteActivity(ctx, ActivitySettlePaymentStatus, SettlePaymentParams{
+		OrderCode: params.OrderCode,
+		Status:    st,
+	}).Get(ctx, nil); err != nil {
+		state.failed(ctx, err)
+		return "", err
+	}
+
+	state.step(ctx, StepDone)
+	return st, nil
+}
+
+func RefundPaymentWorkflow(ctx workflow.Context, params RefundWorkflowParams) (*payment.RefundPaymentResponse, error) {
+	ctx = withActivityOptions(ctx)
+
+	state := PaymentState{OrderCode: params.OrderCode, Amount: params.Amount, Step: StepRefunding}
+	if err := trackPaymentState(ctx, &state); err != nil {
+		return nil, err
+	}
+
+	var res *payment.RefundPaymentResponse
+	if err := workflow.ExecuteActivity(ctx, ActivityIssueRefund, params).Get(ctx, &res); err != nil {
+		state.failed(ctx, err)
+		return nil, err
+	}
+	state.step(ctx, StepDone)
+	return res, nil
+}
+
+// PollPaymentStatusWorkflow catches payments whose callback never arrived.
+// It checks the payment on a doubling interval until it settles or
+// ExpiresAt passes, when a still pending payment is failed as expired; a
+// check failing after its retries, e.g. while the provider is down, waits
+// for the next round.
+func PollPaymentStatusWorkflow(ctx workflow.Context, params PollPaymentParams) (models.PaymentStatus, error) {
+	ctx = withActivityOptions(ctx)
+
+	state := PaymentState{
+		OrderCode: params.OrderCode,
+		PaymentID: params.PaymentID,
+		Provider:  params.Provider,
+		Amount:    params.Amount,
+		Status:    models.PaymentStatusPending,
+	}
+	if err := trackPaymentState(ctx, &state); err != nil {
+		return "", err
+	}
+
+	interval := params.InitialInterval
+	for {
+		if left := params.ExpiresAt.Sub(workflow.Now(ctx)); left < interval {
+			interval = max(left, 0)
+		}
+		state.NextCheckAt = workflow.Now(ctx).Add(interval)
+		state.step(ctx, StepWaiting)
+		if err := workflow.Sleep(ctx, interval); err != nil {
+			return "", err
+		}
+
+		var res PollResult
+		state.step(ctx, StepCheckingStatus)
+		err := workflow.ExecuteActivity(ctx, ActivityCheckPaymentStatus, params).Get(ctx, &res)
+		state.Checks++
+		if err != nil {
+			state.failed(ctx, err)
+		}
+		var appErr *temporal.ApplicationError
+		if errors.As(err, &appErr) && appErr.NonRetryable() {
+			return "", err
+		}
+		if err == nil && res.Done {
+			state.settle(ctx, res.Status)
+			state.step(ctx, StepDone)
+			return res.Status, nil
+		}
+
+		if !workflow.Now(ctx).Before(params.ExpiresAt) {
+			var st models.PaymentStatus
+			state.step(ctx, StepExpiring)
+			if err := workflow.ExecuteActivity(ctx, ActivityExpirePayment, params).Get(ctx, &st); err != nil {
+				workflow.GetLogger(ctx).Error("failed to expire payment", "OrderCode", params.OrderCode, "Error", err)
+				state.failed(ctx, err)
+				state.step(ctx, StepDone)
+				return models.PaymentStatusPending, nil
+			}
+			state.settle(ctx, st)
+			state.step(ctx, StepDone)
+			return st, nil
+		}
+		interval = min(interval*2, params.MaxInterval)
+	}
+}
@@ -0,0 +1,266 @@
+package banktransfer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func newWorkflowEnv(t *testing.T) *testsuite.TestWorkflowEnvironment {
+	t.Helper()
+
+	var s testsuite.WorkflowTestSuite
+	env := s.NewTestWorkflowEnvironment()
+	bankTf.RegisterPaymentWorker(env, &bankTf.PaymentActivities{})
+	t.Cleanup(func() { env.AssertExpectations(t) })
+	return env
+}
+
+func pollParams(env *testsuite.TestWorkflowEnvironment) bankTf.PollPaymentParams {
+	return bankTf.PollPaymentParams{
+		PaymentID:       "payment-1",
+		OrderCode:       "ORD-1",
+		Provider:        string(models.GatewayTypeZalopay),
+		Amount:          50000,
+		InitialInterval: 30 * time.Second,
+		MaxInterval:     5 * time.Minute,
+		ExpiresAt:       env.Now().UTC().Add(30 * time.Minute),
+	}
+}
+
+func TestPollPaymentStatusDoublesIntervalUntilSettled(t *testing.T) {
+	env := newWorkflowEnv(t)
+	start := env.Now()
+
+	var checks []time.Duration
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).Return(
+		func(context.Context, bankTf.PollPaymentParams) (bankTf.PollResult, error) {
+			checks = append(checks, env.Now().Sub(start))
+			if len(checks) < 4 {
+				return bankTf.PollResult{Status: models.PaymentStatusPending}, nil
+			}
+			return bankTf.PollResult{Status: models.PaymentStatusCompleted, Done: true}, nil
+		})
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, pollParams(env))
+
+	require.NoError(t, env.GetWorkflowError())
+	var st models.PaymentStatus
+	require.NoError(t, env.GetWorkflowResult(&st))
+	require.Equal(t, models.PaymentStatusCompleted, st)
+	require.Equal(t, []time.Duration{30 * time.Second, 90 * time.Second, 210 * time.Second, 450 * time.Second}, checks)
+}
+
+func TestPollPaymentStatusExpiresPendingPayment(t *testing.T) {
+	env := newWorkflowEnv(t)
+	params := pollParams(env)
+
+	checks := 0
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).Return(
+		func(context.Context, bankTf.PollPaymentParams) (bankTf.PollResult, error) {
+			checks++
+			return bankTf.PollResult{Status: models.PaymentStatusPending}, nil
+		})
+	env.OnActivity(bankTf.ActivityExpirePayment, mock.Anything, params).Return(models.PaymentStatusFailed, nil).Once()
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, params)
+
+	require.NoError(t, env.GetWorkflowError())
+	var st models.PaymentStatus
+	require.NoError(t, env.GetWorkflowResult(&st))
+	require.Equal(t, models.PaymentStatusFailed, st)
+	// 30s, 1m, 2m, 4m, then every 5m until the last check at expiry.
+	require.Equal(t, 9, checks)
+	require.False(t, env.Now().Before(params.ExpiresAt))
+}
+
+func TestPollPaymentStatusRetriesFailedChecks(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	attempts := 0
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).Return(
+		func(context.Context, bankTf.PollPaymentParams) (bankTf.PollResult, error) {
+			attempts++
+			if attempts < 3 {
+				return bankTf.PollResult{}, errors.New("gateway unavailable")
+			}
+			return bankTf.PollResult{Status: models.PaymentStatusCompleted, Done: true}, nil
+		})
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, pollParams(env))
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, 3, attempts)
+}
+
+func TestPollPaymentStatusWaitsOutProviderOutage(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	attempts := 0
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).Return(
+		func(context.Context, bankTf.PollPaymentParams) (bankTf.PollResult, error) {
+			attempts++
+			// The first round exhausts its 5 attempts; the next round works.
+			if attempts <= 5 {
+				return bankTf.PollResult{}, errors.New("gateway unavailable")
+			}
+			return bankTf.PollResult{Status: models.PaymentStatusCompleted, Done: true}, nil
+		})
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, pollParams(env))
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, 6, attempts)
+}
+
+func TestPollPaymentStatusStopsOnNonRetryableError(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).
+		Return(bankTf.PollResult{}, temporal.NewNonRetryableApplicationError("payment not found", "NotFound", nil)).Once()
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, pollParams(env))
+
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(env.GetWorkflowError(), &appErr))
+	require.Equal(t, "NotFound", appErr.Type())
+}
+
+func TestPollPaymentStatusRetriesTimedOutChecks(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	attempts := 0
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).
+		After(time.Minute).
+		Return(bankTf.PollResult{Status: models.PaymentStatusPending}, nil).Once()
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).Return(
+		func(context.Context, bankTf.PollPaymentParams) (bankTf.PollResult, error) {
+			attempts++
+			return bankTf.PollResult{Status: models.PaymentStatusCompleted, Done: true}, nil
+		})
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, pollParams(env))
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, 1, attempts)
+}
+
+func TestPollPaymentStatusAnswersStateQuery(t *testing.T) {
+	env := newWorkflowEnv(t)
+	params := pollParams(env)
+
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).
+		Return(bankTf.PollResult{Status: models.PaymentStatusPending}, nil).Once()
+	env.OnActivity(bankTf.ActivityCheckPaymentStatus, mock.Anything, mock.Anything).
+		Return(bankTf.PollResult{Status: models.PaymentStatusCompleted, Done: true}, nil).Once()
+
+	var waiting bankTf.PaymentState
+	env.RegisterDelayedCallback(func() {
+		v, err := env.QueryWorkflow(bankTf.QueryPaymentState)
+		require.NoError(t, err)
+		require.NoError(t, v.Get(&waiting))
+	}, 45*time.Second)
+
+	env.ExecuteWorkflow(bankTf.WorkflowPollPaymentStatus, params)
+	require.NoError(t, env.GetWorkflowError())
+
+	require.Equal(t, bankTf.StepWaiting, waiting.Step)
+	require.Equal(t, models.PaymentStatusPending, waiting.Status)
+	require.Equal(t, 1, waiting.Checks)
+	require.Equal(t, params.PaymentID, waiting.PaymentID)
+	require.Equal(t, params.Amount, waiting.Amount)
+
+	v, err := env.QueryWorkflow(bankTf.QueryPaymentState)
+	require.NoError(t, err)
+	var done bankTf.PaymentState
+	require.NoError(t, v.Get(&done))
+	require.Equal(t, bankTf.StepDone, done.Step)
+	require.Equal(t, models.PaymentStatusCompleted, done.Status)
+	require.Equal(t, 2, done.Checks)
+}
+
+func TestSyncPaymentStatusSettlesPayment(t *testing.T) {
+	env := newWorkflowEnv(t)
+	params := bankTf.PaymentWorkflowParams{OrderCode: "ORD-1"}
+
+	env.OnActivity(bankTf.ActivityQueryGatewayStatus, mock.Anything, params).Return(models.PaymentStatusCompleted, nil).Once()
+	env.OnActivity(bankTf.ActivitySettlePaymentStatus, mock.Anything, bankTf.SettlePaymentParams{
+		OrderCode: "ORD-1",
+		Status:    models.PaymentStatusCompleted,
+	}).Return(nil).Once()
+
+	env.ExecuteWorkflow(bankTf.WorkflowSyncPaymentStatus, params)
+
+	require.NoError(t, env.GetWorkflowError())
+	var st models.PaymentStatus
+	require.NoError(t, env.GetWorkflowResult(&st))
+	require.Equal(t, models.PaymentStatusCompleted, st)
+}
+
+func TestSyncPaymentStatusLeavesPendingPayment(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	env.OnActivity(bankTf.ActivityQueryGatewayStatus, mock.Anything, mock.Anything).Return(models.PaymentStatusPending, nil).Once()
+
+	env.ExecuteWorkflow(bankTf.WorkflowSyncPaymentStatus, bankTf.PaymentWorkflowParams{OrderCode: "ORD-1"})
+
+	require.NoError(t, env.GetWorkflowError())
+	var st models.PaymentStatus
+	require.NoError(t, env.GetWorkflowResult(&st))
+	require.Equal(t, models.PaymentStatusPending, st)
+}
+
+func TestCompensatePaymentSkipsSettledOrder(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	env.OnActivity(bankTf.ActivityFindOrder, mock.Anything, "ORD-1").
+		Return(bankTf.CompensationOrder{Status: order.OrderStatus_PAYMENT_SUCCESS}, nil).Once()
+	var recorded bankTf.CompensationResult
+	env.OnActivity(bankTf.ActivityRecordCompensation, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(bankTf.CompensationResult) }).
+		Return(nil).Once()
+
+	env.ExecuteWorkflow(bankTf.WorkflowCompensatePayment, bankTf.CompensationParams{OrderCode: "ORD-1", PaymentID: "payment-1"})
+
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, "order is PAYMENT_SUCCESS", recorded.Skipped)
+	require.Empty(t, recorded.Completed)
+}
+
+func TestCompensatePaymentReportsIncompleteSteps(t *testing.T) {
+	env := newWorkflowEnv(t)
+
+	env.OnActivity(bankTf.ActivityFindOrder, mock.Anything, "ORD-1").Return(bankTf.CompensationOrder{
+		Status: order.OrderStatus_INVENTORY_RESERVED,
+		Items:  []bankTf.InventoryItem{{ProductID: "p1", Quantity: 2}},
+	}, nil).Once()
+	env.OnActivity(bankTf.ActivityUpdateOrderStatus, mock.Anything, mock.Anything).Return(nil).Once()
+	releases := 0
+	env.OnActivity(bankTf.ActivityReleaseInventory, mock.Anything, mock.Anything).Return(
+		func(context.Context, bankTf.ReleaseInventoryParams) error {
+			releases++
+			return errors.New("product service unavailable")
+		})
+	env.OnActivity(bankTf.ActivityRecordCompensation, mock.Anything, mock.Anything).Return(nil).Once()
+
+	env.ExecuteWorkflow(bankTf.WorkflowCompensatePayment, bankTf.CompensationParams{OrderCode: "ORD-1", PaymentID: "payment-1"})
+
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(env.GetWorkflowError(), &appErr))
+	require.Equal(t, "CompensationIncomplete", appErr.Type())
+	require.Equal(t, 10, releases)
+
+	var res bankTf.CompensationResult
+	require.NoError(t, appErr.Details(&res))
+	require.Equal(t, []string{bankTf.CompensationStepFailOrder}, res.Completed)
+	require.Equal(t, []string{bankTf.CompensationStepReleaseInventory}, res.Failed)
+}
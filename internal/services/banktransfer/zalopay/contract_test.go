@@ -0,0 +1,81 @@
+package zalopay_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp/replay"
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay/zalopaytest"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+)
+
+// ZaloPay's public sandbox app. Its keys are published, so they can sign
+// the recorded requests and stay in the fixture.
+const (
+	sandboxAppID = 2553
+	sandboxKey1  = "PcY4iZIKFCIdgZvA6ueMcMHHUbRLYjPL"
+	sandboxKey2  = "kLtgPl8HHhfvMuDHPwKfgfsY4Ydm9eIz"
+)
+
+// contractGateway returns a sandbox gateway whose calls go through the
+// fixture at path. With GATEWAY_FIXTURES=record they reach
+// the sandbox and are saved when the test ends.
+func contractGateway(t *testing.T, path string) *zalopay.ZalopayGateway {
+	t.Helper()
+
+	key1, err := keyring.Single("sandbox", sandboxKey1)
+	require.NoError(t, err)
+	key2, err := keyring.Single("sandbox", sandboxKey2)
+	require.NoError(t, err)
+
+	gw := zalopay.New(sandboxAppID, key1, key2, "https://payments.example.com")
+
+	c, err := replay.New(path, replay.ModeFromEnv(), zalopaytest.Scrubber())
+	require.NoError(t, err)
+	zalopaytest.UseCassette(gw, c)
+	t.Cleanup(func() {
+		require.Empty(t, c.Unused(), "fixture has calls the gateway no longer makes")
+		require.NoError(t, c.Save())
+	})
+
+	return gw
+}
+
+// TestZalopayContract checks the create, query and bank list calls against
+// testdata/contract.json. The gateway runs on the real clock, so the
+// fixture can be re-recorded against the sandbox with
+// GATEWAY_FIXTURES=record at any time; the MACs it scrubs are checked by
+// TestRequestMacs.
+func TestZalopayContract(t *testing.T) {
+	gw := contractGateway(t, "testdata/contract.json")
+	ctx := context.Background()
+
+	res, err := gw.ProcessPayment(ctx, &payment.ProcessPaymentRequest{
+		OrderCode: "ORD-CONTRACT-1",
+		Amount:    50000,
+		UserId:    "user-1",
+		Mode:      payment.PaymentMode_PAYMENT_MODE_REDIRECT,
+	}, &order.OrderData{
+		Code:   "ORD-CONTRACT-1",
+		UserId: "user-1",
+		Items: []*order.OrderItem{
+			{ProductId: "p1", ProductName: "Áo thun", ProductPrice: 25000, Quantity: 2},
+		},
+	})
+	require.NoError(t, err)
+	require.Regexp(t, `^\d{6}_ORD-CONTRACT-1$`, res.Payment.Id)
+	require.NotEmpty(t, res.PaymentUrl)
+
+	st, err := gw.QueryPaymentStatus(ctx, models.Payment{GatewayReference: res.Payment.Id})
+	require.NoError(t, err)
+	require.Equal(t, models.PaymentStatusPending, st)
+
+	banks, err := gw.ListBanks(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, banks)
+}
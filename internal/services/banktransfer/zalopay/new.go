@@ -1,31 +1,186 @@
 package zalopay
 
 import (
-	"net/http"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/vogiaan1904/payment-svc/config"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+	"github.com/vogiaan1904/payment-svc/internal/models"
 	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
 )
 
+// Endpoint names used in HTTP logs and metrics.
+const (
+	endpointCreate   = "create"
+	endpointQuery    = "query"
+	endpointRefund   = "refund"
+	endpointBankList = "bank_list"
+)
+
+// lookupTimeout bounds each attempt of the query and bank list calls, which
+// callers wait on and which are retried, unlike create and refund, which
+// keep the client's timeout.
+const lookupTimeout = 5 * time.Second
+
+// defaultBankListTTL is how long the getlistmerchantbanks result is reused.
+// ZaloPay changes the list rarely and rate limits the endpoint.
+const defaultBankListTTL = time.Hour
+
+// Query parameters ZaloPay appends to redirecturl, next to our bookingCode.
+const (
+	redirectParamAppID          = "appid"
+	redirectParamAppTransID     = "apptransid"
+	redirectParamPmcID          = "pmcid"
+	redirectParamBankCode       = "bankcode"
+	redirectParamAmount         = "amount"
+	redirectParamDiscountAmount = "discountamount"
+	redirectParamStatus         = "status"
+	redirectParamChecksum       = "checksum"
+	redirectParamBookingCode    = "bookingCode"
+
+	redirectStatusSuccess = "1"
+)
+
+// Callback return codes defined by ZaloPay; anything else, CallbackErrorCode
+// included, is a permanent rejection.
+const (
+	callbackCodeSuccess = 1
+	callbackCodeRetry   = 2
+)
+
+// Query return codes defined by ZaloPay. A failure with one of the
+// queryRequestErrors sub-codes means the query itself was rejected, e.g.
+// for a bad MAC, and says nothing about the order.
+const (
+	queryCodePaid       = 1
+	queryCodeFailed     = 2
+	queryCodeProcessing = 3
+)
+
+var queryRequestErrors = map[int]bool{
+	-92:  true, // order not found, e.g. not yet visible to the query API
+	-401: true, // invalid request data
+	-402: true, // invalid MAC
+	-500: true, // system error
+}
+
+type environmentURLs struct {
+	create   string
+	query    string
+	refund   string
+	bankList string
+}
+
+var environments = map[string]environmentURLs{
+	config.EnvSandbox: {
+		create:   "https://sb-openapi.zalopay.vn/v2/create",
+		query:    "https://sb-openapi.zalopay.vn/v2/query",
+		refund:   "https://sb-openapi.zalopay.vn/v2/refund",
+		bankList: "https://sbgateway.zalopay.vn/api/getlistmerchantbanks",
+	},
+	config.EnvProduction: {
+		create:   "https://openapi.zalopay.vn/v2/create",
+		query:    "https://openapi.zalopay.vn/v2/query",
+		refund:   "https://openapi.zalopay.vn/v2/refund",
+		bankList: "https://gateway.zalopay.vn/api/getlistmerchantbanks",
+	},
+}
+
+// sandboxKeys are the key1 and key2 values of ZaloPay's public sandbox
+// apps, which must never be used against production.
+var sandboxKeys = map[string]bool{
+	"PcY4iZIKFCIdgZvA6ueMcMHHUbRLYjPL": true, // 2553 key1
+	"kLtgPl8HHhfvMuDHPwKfgfsY4Ydm9eIz": true, // 2553 key2
+	"sdngKKJmqEMzvh5QQcdD2A9XBSKUNaYn": true, // 2554 key1
+	"trMrHtvjo6myautxDUiAcYsVtaeQ8nhf": true, // 2554 key2
+}
+
 type ZalopayGateway struct {
 	OrderTimeoutSeconds         int
 	CreateZalopayPaymentLinkURL string
+	QueryURL                    string
+	RefundURL                   string
+	BankListURL                 string
+	BankListTTL                 time.Duration
 	AppID                       int
-	Key1                        string
-	Key2                        string
+	Key1                        *keyring.Keyring
+	Key2                        *keyring.Keyring
 	CallbackErrorCode           int
-	HttpClient                  *http.Client
+	HTTP                        *gatewayhttp.Client
 	Host                        string
+	CallbackPath                string
+	ReturnPath                  string
+	DescriptionTemplates        map[string]string
+	DefaultLocale               string
+	// Now stamps app_time, timestamps and IDs of outgoing requests. Tests
+	// pin it so the requests, and so their MACs, are reproducible.
+	Now func() time.Time
+
+	env string
+
+	banksMu        sync.Mutex
+	banks          []bankTf.Bank
+	banksFetchedAt time.Time
 }
 
-func New(appID int, key1 string, key2 string, host string) bankTf.PaymentGateway {
+// New takes key1, which signs requests to ZaloPay, and key2, which verifies
+// its callbacks, as keyrings so either can be rotated.
+func New(appID int, key1 *keyring.Keyring, key2 *keyring.Keyring, host string) *ZalopayGateway {
 	return &ZalopayGateway{
 		OrderTimeoutSeconds:         300,
-		CreateZalopayPaymentLinkURL: "https://sb-openapi.zalopay.vn/v2/create",
+		CreateZalopayPaymentLinkURL: environments[config.EnvSandbox].create,
+		QueryURL:                    environments[config.EnvSandbox].query,
+		RefundURL:                   environments[config.EnvSandbox].refund,
+		BankListURL:                 environments[config.EnvSandbox].bankList,
+		env:                         config.EnvSandbox,
+		BankListTTL:                 defaultBankListTTL,
 		AppID:                       appID,
 		Key1:                        key1,
 		Key2:                        key2,
 		CallbackErrorCode:           -1,
-		HttpClient:                  &http.Client{},
+		HTTP:                        gatewayhttp.New(string(models.GatewayTypeZalopay), nil, gatewayhttp.Settings{}),
 		Host:                        host,
+		CallbackPath:                "/callbacks/zalopay",
+		DescriptionTemplates:        map[string]string{"vi": "Thanh toán đơn hàng #" + orderCodePlaceholder},
+		DefaultLocale:               "vi",
+		Now:                         time.Now,
 	}
 }
+
+// SetEnvironment points the gateway at the sandbox or production API.
+func (g *ZalopayGateway) SetEnvironment(env string) error {
+	urls, ok := environments[env]
+	if !ok {
+		return fmt.Errorf("unknown zalopay environment %q", env)
+	}
+
+	g.CreateZalopayPaymentLinkURL = urls.create
+	g.QueryURL = urls.query
+	g.RefundURL = urls.refund
+	g.BankListURL = urls.bankList
+	g.env = env
+	return nil
+}
+
+func (g *ZalopayGateway) Environment() string {
+	return g.env
+}
+
+func (g *ZalopayGateway) Routes() bankTf.Routes {
+	return bankTf.Routes{Callback: g.CallbackPath, Return: g.ReturnPath}
+}
+
+// PaymentMethod: ZaloPay settles to the merchant itself, even when the
+// customer picks a bank channel.
+func (g *ZalopayGateway) PaymentMethod() models.PaymentMethod {
+	return models.PaymentMethodOnline
+}
+
+// IsSandboxKey reports whether key is the key1 or key2 of one of ZaloPay's
+// public sandbox apps.
+func IsSandboxKey(key string) bool {
+	return sandboxKeys[key]
+}
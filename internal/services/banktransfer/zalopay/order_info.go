@@ -0,0 +1,83 @@
+package zalopay
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+)
+
+// Field limits of the create API, in characters.
+const (
+	maxAppTransIDLen  = 40
+	maxAppUserLen     = 50
+	maxDescriptionLen = 256
+	maxItemLen        = 2048
+	maxItemNameLen    = 100
+)
+
+const (
+	// metadataLocale picks the description template, e.g. "en".
+	metadataLocale = "locale"
+
+	orderCodePlaceholder = "{order_code}"
+)
+
+type zaloPayItem struct {
+	ItemID       string `json:"itemid"`
+	ItemName     string `json:"itemname"`
+	ItemPrice    int64  `json:"itemprice"`
+	ItemQuantity int32  `json:"itemquantity"`
+}
+
+// appUser identifies the buyer in the ZaloPay portal.
+func appUser(ord *order.OrderData, fallback string) string {
+	user := ord.GetUserId()
+	if user == "" {
+		user = fallback
+	}
+	return truncate(user, maxAppUserLen)
+}
+
+// itemJSON lists the order items. Items that would push the field past
+// ZaloPay's limit are left out rather than sending a truncated array.
+func itemJSON(ord *order.OrderData) string {
+	items := make([]zaloPayItem, 0, len(ord.GetItems()))
+	for _, it := range ord.GetItems() {
+		items = append(items, zaloPayItem{
+			ItemID:       it.ProductId,
+			ItemName:     truncate(it.ProductName, maxItemNameLen),
+			ItemPrice:    int64(it.ProductPrice),
+			ItemQuantity: it.Quantity,
+		})
+	}
+
+	for ; len(items) > 0; items = items[:len(items)-1] {
+		b, err := json.Marshal(items)
+		if err == nil && utf8.RuneCount(b) <= maxItemLen {
+			return string(b)
+		}
+	}
+	return "[]"
+}
+
+// description fills the template for the request locale, falling back to
+// the default locale and then to the bare order code.
+func (g *ZalopayGateway) description(orderCode string, locale string) string {
+	tmpl, ok := g.DescriptionTemplates[locale]
+	if !ok {
+		tmpl, ok = g.DescriptionTemplates[g.DefaultLocale]
+	}
+	if !ok {
+		tmpl = orderCodePlaceholder
+	}
+	return truncate(strings.ReplaceAll(tmpl, orderCodePlaceholder, orderCode), maxDescriptionLen)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -1,13 +1,18 @@
 package zalopay
 
+import "encoding/json"
+
 // ZalopayGateway struct is now defined in zalo_gateway.go
 
 type ZaloPayRequestConfigInterface struct {
 	OrderCode   string
 	Amount      int64
+	AppUser     string
+	Item        string
 	Description string
 	ReturnURL   string
 	Host        string
+	Channel     channel
 }
 
 type ZaloPayCallbackResponse struct {
@@ -17,6 +22,7 @@ type ZaloPayCallbackResponse struct {
 
 type TransactionData struct {
 	AppTransID string `json:"app_trans_id"`
+	Amount     int64  `json:"amount"`
 }
 
 type ZalopayCallbackData struct {
@@ -25,13 +31,35 @@ type ZalopayCallbackData struct {
 }
 
 type CallbackResult struct {
-	Success   bool
-	Response  ZaloPayCallbackResponse
-	OrderCode string
+	Success  bool
+	Response ZaloPayCallbackResponse
 }
 
 type embedData struct {
-	RedirectURL string `json:"redirecturl"`
+	RedirectURL            string   `json:"redirecturl"`
+	PreferredPaymentMethod []string `json:"preferred_payment_method,omitempty"`
+}
+
+// channel is where ZaloPay sends the user: bank_code picks a method or a
+// specific bank, preferred narrows the ZaloPay page when bank_code is empty.
+type channel struct {
+	BankCode  string
+	Preferred []string
+}
+
+type zaloPayBankListResponse struct {
+	ReturnCode    int                      `json:"returncode"`
+	ReturnMessage string                   `json:"returnmessage"`
+	Banks         map[string][]zaloPayBank `json:"banks"`
+}
+
+type zaloPayBank struct {
+	BankCode     string  `json:"bankcode"`
+	Name         string  `json:"name"`
+	DisplayOrder int     `json:"displayorder"`
+	PmcID        int     `json:"pmcid"`
+	MinAmount    float64 `json:"minamount"`
+	MaxAmount    float64 `json:"maxamount"`
 }
 
 type ZaloPayRequestConfig struct {
@@ -49,12 +77,50 @@ type ZaloPayRequestConfig struct {
 	Mac                string `json:"mac"`
 }
 
+// zaloPayCreateResponse carries one field per payment mode: order_url for
+// the web page, qr_code for desktop checkout and zp_trans_token for the SDK.
+type zaloPayCreateResponse struct {
+	ReturnCode       int    `json:"return_code"`
+	ReturnMessage    string `json:"return_message"`
+	SubReturnCode    int    `json:"sub_return_code"`
+	SubReturnMessage string `json:"sub_return_message"`
+	OrderURL         string `json:"order_url"`
+	OrderToken       string `json:"order_token"`
+	ZpTransToken     string `json:"zp_trans_token"`
+	QRCode           string `json:"qr_code"`
+	Deeplink         string `json:"deeplink"`
+}
+
+type zaloPayQueryRequest struct {
+	AppID      int    `json:"app_id"`
+	AppTransID string `json:"app_trans_id"`
+	Mac        string `json:"mac"`
+}
+
 type zaloPayStatusResponse struct {
+	ReturnCode       int         `json:"return_code"`
+	ReturnMessage    string      `json:"return_message"`
+	SubReturnCode    int         `json:"sub_return_code"`
+	SubReturnMessage string      `json:"sub_return_message"`
+	IsProcessing     bool        `json:"is_processing"`
+	Amount           int64       `json:"amount"`
+	ZpTransID        json.Number `json:"zp_trans_id"`
+}
+
+type zaloPayRefundRequest struct {
+	AppID       int    `json:"app_id"`
+	MRefundID   string `json:"m_refund_id"`
+	ZpTransID   string `json:"zp_trans_id"`
+	Amount      int64  `json:"amount"`
+	Timestamp   int64  `json:"timestamp"`
+	Description string `json:"description"`
+	Mac         string `json:"mac"`
+}
+
+type zaloPayRefundResponse struct {
 	ReturnCode       int    `json:"return_code"`
 	ReturnMessage    string `json:"return_message"`
 	SubReturnCode    int    `json:"sub_return_code"`
 	SubReturnMessage string `json:"sub_return_message"`
-	IsProcessing     int    `json:"is_processing"`
-	Amount           int64  `json:"amount"`
-	ZpTransID        string `json:"zp_trans_id"`
+	RefundID         int64  `json:"refund_id"`
 }
@@ -1,29 +1,34 @@
 package zalopay
 
 import (
-	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
+	"strconv"
 	"strings"
-	"time"
+	"unicode/utf8"
 
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
 	"github.com/vogiaan1904/payment-svc/internal/models"
 	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
 	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
-	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-func (z *ZalopayGateway) initZaloPayRequestConfig(data ZaloPayRequestConfigInterface) ZaloPayRequestConfig {
-	now := time.Now()
+func (z *ZalopayGateway) initZaloPayRequestConfig(data ZaloPayRequestConfigInterface) (ZaloPayRequestConfig, error) {
+	now := z.Now()
 	transID := now.Format("060102") // YY MM DD format
 
+	// app_trans_id cannot be shortened: callbacks find the order by it.
+	appTransID := fmt.Sprintf("%s_%s", transID, data.OrderCode)
+	if n := utf8.RuneCountInString(appTransID); n > maxAppTransIDLen {
+		return ZaloPayRequestConfig{}, fmt.Errorf("%w: app_trans_id %s has %d characters, ZaloPay takes at most %d",
+			bankTf.ErrInvalidInput, appTransID, n, maxAppTransIDLen)
+	}
+
 	returnURL := data.ReturnURL
 	if strings.Contains(returnURL, "?") {
 		returnURL += fmt.Sprintf("&bookingCode=%s", data.OrderCode)
@@ -32,23 +37,23 @@ func (z *ZalopayGateway) initZaloPayRequestConfig(data ZaloPayRequestConfigInter
 	}
 
 	embedDataObj := embedData{
-		RedirectURL: returnURL,
+		RedirectURL:            returnURL,
+		PreferredPaymentMethod: data.Channel.Preferred,
 	}
 	embedDataJSON, _ := json.Marshal(embedDataObj)
-	itemJSON, _ := json.Marshal([]interface{}{})
 
 	config := ZaloPayRequestConfig{
 		AppID:              z.AppID,
-		AppUser:            "user123",
+		AppUser:            data.AppUser,
 		AppTime:            now.UnixMilli(),
 		Amount:             data.Amount,
-		AppTransID:         fmt.Sprintf("%s_%s", transID, data.OrderCode),
+		AppTransID:         appTransID,
 		EmbedData:          string(embedDataJSON),
 		ExpireDurationSecs: z.OrderTimeoutSeconds,
 		Description:        data.Description,
-		BankCode:           "",
-		CallbackURL:        data.Host + "/zalopay/callback",
-		Item:               string(itemJSON),
+		BankCode:           data.Channel.BankCode,
+		CallbackURL:        data.Host + z.CallbackPath,
+		Item:               data.Item,
 		Mac:                "",
 	}
 
@@ -62,74 +67,52 @@ func (z *ZalopayGateway) initZaloPayRequestConfig(data ZaloPayRequestConfigInter
 		config.Item,
 	)
 
-	h := hmac.New(sha256.New, []byte(z.Key1))
-	h.Write([]byte(macInput))
-	config.Mac = hex.EncodeToString(h.Sum(nil))
+	mac, err := z.sign(macInput)
+	if err != nil {
+		return ZaloPayRequestConfig{}, err
+	}
+	config.Mac = mac
 
-	return config
+	return config, nil
 }
 
-func (g *ZalopayGateway) ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest) (*payment.ProcessPaymentResponse, error) {
-	log.Printf("ProcessPayment request metadata: %+v", req.Metadata)
-
+func (g *ZalopayGateway) ProcessPayment(ctx context.Context, req *payment.ProcessPaymentRequest, ord *order.OrderData) (*payment.ProcessPaymentResponse, error) {
+	// Without a return_url of its own, the customer comes back through the
+	// landing endpoint, which verifies the redirect before the frontend sees it.
 	returnURL := req.Metadata["return_url"]
+	if returnURL == "" && g.ReturnPath != "" {
+		returnURL = g.Host + g.ReturnPath
+	}
 	if returnURL == "" {
 		returnURL = "http://localhost:3000/payment/success"
-		log.Printf("No return_url in metadata, using fallback: %s", returnURL)
-	} else {
-		log.Printf("Using return_url from metadata: %s", returnURL)
 	}
 
-	data := g.initZaloPayRequestConfig(ZaloPayRequestConfigInterface{
+	data, err := g.initZaloPayRequestConfig(ZaloPayRequestConfigInterface{
 		OrderCode:   req.OrderCode,
 		Amount:      int64(req.Amount),
-		Description: "E-Commerce",
+		AppUser:     appUser(ord, req.UserId),
+		Item:        itemJSON(ord),
+		Description: g.description(req.OrderCode, req.Metadata[metadataLocale]),
 		ReturnURL:   returnURL,
 		Host:        g.Host,
+		Channel:     resolveChannel(req.ProviderDetails),
 	})
-
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return nil, bankTf.ErrInternal
-	}
-
-	// Add debug logging to see what we're sending
-	log.Printf("Sending to ZaloPay: %s", string(jsonData))
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", g.CreateZalopayPaymentLinkURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, bankTf.ErrInternal
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := g.HttpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-	log.Printf("zalopay response body: %s\n", string(bodyBytes))
-
-	type zaloPayResponse struct {
-		ReturnCode int    `json:"return_code"`
-		OrderURL   string `json:"order_url"`
+		return nil, err
 	}
 
-	var zaloResp zaloPayResponse
-	if err := json.Unmarshal(bodyBytes, &zaloResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var zaloResp zaloPayCreateResponse
+	if err := g.HTTP.PostJSON(ctx, endpointCreate, g.CreateZalopayPaymentLinkURL, data, &zaloResp, gatewayhttp.CallOptions{}); err != nil {
+		return nil, err
 	}
 
 	if zaloResp.ReturnCode != 1 {
 		return nil, fmt.Errorf("zalopay error: return_code=%d", zaloResp.ReturnCode)
 	}
 
-	return &payment.ProcessPaymentResponse{
+	res := &payment.ProcessPaymentResponse{
 		PaymentUrl: zaloResp.OrderURL,
+		Mode:       req.Mode,
 		Payment: &payment.PaymentData{
 			Id:              data.AppTransID,
 			OrderCode:       req.OrderCode,
@@ -138,96 +121,216 @@ func (g *ZalopayGateway) ProcessPayment(ctx context.Context, req *payment.Proces
 			ProviderDetails: req.ProviderDetails,
 			Metadata:        req.Metadata,
 		},
-	}, nil
-}
+	}
 
-func (g *ZalopayGateway) HandleCallback(ctx context.Context, callbackData interface{}) (string, error) {
-	zpCallbackData, ok := callbackData.(ZalopayCallbackData)
-	if !ok {
-		return "", fmt.Errorf("invalid callback data")
+	switch req.Mode {
+	case payment.PaymentMode_PAYMENT_MODE_QR:
+		res.Payload = &payment.ProcessPaymentResponse_Qr{Qr: &payment.QRPayload{QrCode: zaloResp.QRCode}}
+	case payment.PaymentMode_PAYMENT_MODE_APP:
+		res.Payload = &payment.ProcessPaymentResponse_App{App: &payment.AppPayload{Token: zaloResp.ZpTransToken, Deeplink: zaloResp.Deeplink}}
 	}
 
-	h := hmac.New(sha256.New, []byte(g.Key2))
-	h.Write([]byte(zpCallbackData.Data))
-	requestMac := hex.EncodeToString(h.Sum(nil))
+	return res, nil
+}
+
+// ParseCallback verifies the callback MAC against every active key2, so
+// callbacks signed before a rotation still go through. ZaloPay only calls
+// back for successful payments; app_trans_id is "yymmdd_<order code>".
+func (g *ZalopayGateway) ParseCallback(ctx context.Context, r *http.Request) (bankTf.CallbackEvent, error) {
+	var zpCallbackData ZalopayCallbackData
+	if err := json.NewDecoder(r.Body).Decode(&zpCallbackData); err != nil {
+		return bankTf.CallbackEvent{}, fmt.Errorf("%w: %v", bankTf.ErrInvalidCallback, err)
+	}
 
-	if requestMac != zpCallbackData.Mac {
-		return "", fmt.Errorf("invalid mac")
+	keyVersion, ok := g.Key2.Verify(zpCallbackData.Data, zpCallbackData.Mac)
+	if !ok {
+		return bankTf.CallbackEvent{}, bankTf.ErrInvalidSignature
 	}
 
 	var transData TransactionData
 	if err := json.Unmarshal([]byte(zpCallbackData.Data), &transData); err != nil {
-		return "", fmt.Errorf("failed to parse transaction data: %w", err)
+		return bankTf.CallbackEvent{}, fmt.Errorf("%w: failed to parse transaction data: %v", bankTf.ErrInvalidCallback, err)
 	}
 
-	parts := strings.Split(transData.AppTransID, "_")
-	if len(parts) < 2 {
-		return "", fmt.Errorf("invalid app_trans_id format")
+	_, oCode, ok := strings.Cut(transData.AppTransID, "_")
+	if !ok || oCode == "" {
+		return bankTf.CallbackEvent{}, fmt.Errorf("%w: invalid app_trans_id format", bankTf.ErrInvalidCallback)
 	}
-	fmt.Printf("TransData: %+v\n", transData)
 
-	//TODO: store transaction data to database
+	return bankTf.CallbackEvent{
+		OrderCode:        oCode,
+		Status:           models.PaymentStatusCompleted,
+		GatewayReference: transData.AppTransID,
+		Amount:           float64(transData.Amount),
+		KeyVersion:       keyVersion,
+	}, nil
+}
 
-	return transData.AppTransID, nil
+// ParseRedirect verifies the query string ZaloPay appends to redirecturl:
+// checksum is the HMAC of appid|apptransid|pmcid|bankcode|amount|
+// discountamount|status under key2, like callbacks. Status 1 is a
+// successful payment; any other is reported as failed.
+func (g *ZalopayGateway) ParseRedirect(ctx context.Context, r *http.Request) (bankTf.RedirectEvent, error) {
+	q := r.URL.Query()
+	data := strings.Join([]string{
+		q.Get(redirectParamAppID),
+		q.Get(redirectParamAppTransID),
+		q.Get(redirectParamPmcID),
+		q.Get(redirectParamBankCode),
+		q.Get(redirectParamAmount),
+		q.Get(redirectParamDiscountAmount),
+		q.Get(redirectParamStatus),
+	}, "|")
+	if _, ok := g.Key2.Verify(data, q.Get(redirectParamChecksum)); !ok {
+		return bankTf.RedirectEvent{}, bankTf.ErrInvalidSignature
+	}
+
+	if q.Get(redirectParamAppID) != strconv.Itoa(g.AppID) {
+		return bankTf.RedirectEvent{}, fmt.Errorf("%w: redirect for app %s", bankTf.ErrInvalidCallback, q.Get(redirectParamAppID))
+	}
+
+	appTransID := q.Get(redirectParamAppTransID)
+	_, oCode, ok := strings.Cut(appTransID, "_")
+	if !ok || oCode == "" {
+		return bankTf.RedirectEvent{}, fmt.Errorf("%w: invalid apptransid format", bankTf.ErrInvalidCallback)
+	}
+	// bookingCode is ours, appended to redirecturl, and not covered by the
+	// checksum; it only has to agree with the signed apptransid.
+	if bc := q.Get(redirectParamBookingCode); bc != "" && bc != oCode {
+		return bankTf.RedirectEvent{}, fmt.Errorf("%w: bookingCode %s does not match apptransid", bankTf.ErrInvalidCallback, bc)
+	}
+
+	amount, err := strconv.ParseInt(q.Get(redirectParamAmount), 10, 64)
+	if err != nil {
+		return bankTf.RedirectEvent{}, fmt.Errorf("%w: invalid amount: %v", bankTf.ErrInvalidCallback, err)
+	}
+
+	st := models.PaymentStatusFailed
+	if q.Get(redirectParamStatus) == redirectStatusSuccess {
+		st = models.PaymentStatusCompleted
+	}
+
+	return bankTf.RedirectEvent{
+		OrderCode:        oCode,
+		Status:           st,
+		GatewayReference: appTransID,
+		Amount:           float64(amount),
+	}, nil
+}
+
+// AcknowledgeCallback always answers 200 and reports the outcome in
+// return_code, which is all ZaloPay looks at: 1 settles the callback, 2 has
+// ZaloPay retry it, and CallbackErrorCode rejects it for good.
+func (g *ZalopayGateway) AcknowledgeCallback(w http.ResponseWriter, err error) {
+	res := g.callbackResult(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res.Response)
+}
+
+func (g *ZalopayGateway) callbackResult(err error) CallbackResult {
+	switch {
+	case err == nil:
+		return CallbackResult{Success: true, Response: ZaloPayCallbackResponse{ReturnCode: callbackCodeSuccess, ReturnMessage: "success"}}
+	case errors.Is(err, bankTf.ErrDuplicateCallback):
+		return CallbackResult{Success: true, Response: ZaloPayCallbackResponse{ReturnCode: callbackCodeSuccess, ReturnMessage: "duplicate"}}
+	case errors.Is(err, bankTf.ErrInvalidCallback):
+		return CallbackResult{Response: ZaloPayCallbackResponse{ReturnCode: g.CallbackErrorCode, ReturnMessage: err.Error()}}
+	default:
+		return CallbackResult{Response: ZaloPayCallbackResponse{ReturnCode: callbackCodeRetry, ReturnMessage: bankTf.ErrInternal.Error()}}
+	}
+}
+
+// QueryPaymentStatus asks ZaloPay for the state of the order identified by
+// the app_trans_id stored as the payment's gateway reference.
+func (g *ZalopayGateway) QueryPaymentStatus(ctx context.Context, p models.Payment) (models.PaymentStatus, error) {
+	zaloResp, err := g.queryOrder(ctx, p.GatewayReference)
+	if err != nil {
+		return "", err
+	}
+
+	// Only a definite failure fails the payment: anything else is retried,
+	// and a payment ZaloPay never settles expires with its poll.
+	switch {
+	case zaloResp.ReturnCode == queryCodePaid:
+		return models.PaymentStatusCompleted, nil
+	case zaloResp.ReturnCode == queryCodeProcessing || zaloResp.IsProcessing:
+		return models.PaymentStatusPending, nil
+	case zaloResp.ReturnCode == queryCodeFailed && !queryRequestErrors[zaloResp.SubReturnCode]:
+		return models.PaymentStatusFailed, nil
+	default:
+		return "", fmt.Errorf("%w: zalopay query error: return_code=%d, sub_return_code=%d, message=%s", bankTf.ErrGatewayUnavailable,
+			zaloResp.ReturnCode, zaloResp.SubReturnCode, zaloResp.SubReturnMessage)
+	}
+}
+
+// RefundPayment refunds through ZaloPay's refund API. ZaloPay needs its own
+// zp_trans_id, which is looked up with the query API first. m_refund_id is
+// built from the refund alone, so ZaloPay sees a retry as the same refund.
+func (g *ZalopayGateway) RefundPayment(ctx context.Context, p models.Payment, rf models.PaymentRefund) (string, error) {
+	order, err := g.queryOrder(ctx, p.GatewayReference)
+	if err != nil {
+		return "", err
+	}
+	if order.ReturnCode != queryCodePaid {
+		return "", fmt.Errorf("zalopay order %s is not paid: return_code=%d", p.GatewayReference, order.ReturnCode)
+	}
+
+	reason := rf.Reason
+	if reason == "" {
+		reason = "Refund " + p.OrderCode
+	}
+
+	req := zaloPayRefundRequest{
+		AppID:       g.AppID,
+		MRefundID:   fmt.Sprintf("%s_%d_%s", rf.CreatedAt.Format("060102"), g.AppID, rf.ID),
+		ZpTransID:   order.ZpTransID.String(),
+		Amount:      int64(rf.Amount),
+		Timestamp:   g.Now().UnixMilli(),
+		Description: reason,
+	}
+	req.Mac, err = g.sign(fmt.Sprintf("%d|%s|%d|%s|%d", req.AppID, req.ZpTransID, req.Amount, req.Description, req.Timestamp))
+	if err != nil {
+		return "", err
+	}
+
+	var refundResp zaloPayRefundResponse
+	if err := g.HTTP.PostJSON(ctx, endpointRefund, g.RefundURL, req, &refundResp, gatewayhttp.CallOptions{}); err != nil {
+		return "", err
+	}
+
+	// return_code 3 means the refund is still processing, which ZaloPay
+	// completes on its own.
+	if refundResp.ReturnCode != 1 && refundResp.ReturnCode != 3 {
+		return "", fmt.Errorf("zalopay refund error: return_code=%d, sub_return_code=%d, message=%s",
+			refundResp.ReturnCode, refundResp.SubReturnCode, refundResp.SubReturnMessage)
+	}
+
+	return req.MRefundID, nil
 }
 
-func (g *ZalopayGateway) CancelPayment(ctx context.Context, req *payment.CancelPaymentRequest) (*emptypb.Empty, error) {
-	return &emptypb.Empty{}, nil
+func (g *ZalopayGateway) queryOrder(ctx context.Context, appTransID string) (zaloPayStatusResponse, error) {
+	req := zaloPayQueryRequest{
+		AppID:      g.AppID,
+		AppTransID: appTransID,
+	}
+	// The query MAC embeds key1 itself, so both must be the same version.
+	key1, err := g.Key1.Current()
+	if err != nil {
+		return zaloPayStatusResponse{}, err
+	}
+	req.Mac = keyring.HMAC(key1.Secret, fmt.Sprintf("%d|%s|%s", req.AppID, req.AppTransID, key1.Secret))
+
+	var zaloResp zaloPayStatusResponse
+	if err := g.HTTP.PostJSON(ctx, endpointQuery, g.QueryURL, req, &zaloResp, gatewayhttp.CallOptions{Idempotent: true, Timeout: lookupTimeout}); err != nil {
+		return zaloPayStatusResponse{}, err
+	}
+	return zaloResp, nil
 }
 
-// func (g *ZalopayGateway) GetPaymentStatus(ctx context.Context, req *payment.GetPaymentStatusRequest) (*payment.GetPaymentStatusResponse, error) {
-// 	macData := fmt.Sprintf("%d|%s|%s", g.AppID, req.PaymentId, g.Key1)
-
-// 	h := hmac.New(sha256.New, []byte(g.Key1))
-// 	h.Write([]byte(macData))
-// 	mac := hex.EncodeToString(h.Sum(nil))
-
-// 	requestBody := map[string]interface{}{
-// 		"app_id":       g.AppID,
-// 		"app_trans_id": req.PaymentId,
-// 		"mac":          mac,
-// 	}
-
-// 	jsonData, err := json.Marshal(requestBody)
-// 	if err != nil {
-// 		return nil, fmt.Errorf("failed to marshal request: %w", err)
-// 	}
-
-// 	request, err := http.NewRequestWithContext(ctx, "POST", "https://sb-openapi.zalopay.vn/v2/query", bytes.NewBuffer(jsonData))
-// 	if err != nil {
-// 		return nil, fmt.Errorf("failed to create request: %w", err)
-// 	}
-// 	request.Header.Set("Content-Type", "application/json")
-
-// 	response, err := g.HttpClient.Do(request)
-// 	if err != nil {
-// 		return nil, fmt.Errorf("failed to send request: %w", err)
-// 	}
-// 	defer response.Body.Close()
-
-// 	var zaloResp zaloPayStatusResponse
-// 	if err := json.NewDecoder(response.Body).Decode(&zaloResp); err != nil {
-// 		return nil, fmt.Errorf("failed to decode response: %w", err)
-// 	}
-
-// 	var status payment.PaymentStatus
-// 	switch {
-// 	case zaloResp.ReturnCode == 1 && zaloResp.SubReturnCode == 1:
-// 		status = payment.PaymentStatus_PAYMENT_STATUS_COMPLETED
-// 	case zaloResp.IsProcessing == 1 || zaloResp.ReturnCode == 2:
-// 		status = payment.PaymentStatus_PAYMENT_STATUS_PENDING
-// 	case zaloResp.ReturnCode == 2:
-// 		status = payment.PaymentStatus_PAYMENT_STATUS_FAILED
-// 	default:
-// 		status = payment.PaymentStatus_PAYMENT_STATUS_FAILED
-// 	}
-
-// 	return &payment.GetPaymentStatusResponse{
-// 		Payment: &payment.PaymentData{
-// 			Id:        req.PaymentId,
-// 			OrderCode: zaloResp.ZpTransID,
-// 			Amount:    float64(zaloResp.Amount),
-// 			Status:    status,
-// 		},
-// 	}, nil
-// }
+// sign signs an outbound request with the current key1.
+func (g *ZalopayGateway) sign(data string) (string, error) {
+	mac, _, err := g.Key1.Sign(data)
+	return mac, err
+}
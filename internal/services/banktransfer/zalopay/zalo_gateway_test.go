@@ -0,0 +1,283 @@
+package zalopay_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	bankTf "github.com/vogiaan1904/payment-svc/internal/services/banktransfer"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay/zalopaytest"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/order"
+	"github.com/vogiaan1904/payment-svc/protogen/golang/payment"
+)
+
+// gatewayEnv is a gateway talking to the fake, whose callbacks land on a
+// handler that parses and acknowledges them like cmd/http does.
+type gatewayEnv struct {
+	gw *zalopay.ZalopayGateway
+	zp *zalopaytest.Server
+
+	mu     sync.Mutex
+	events []bankTf.CallbackEvent
+	errs   []error
+}
+
+func newGatewayEnv(t *testing.T) *gatewayEnv {
+	t.Helper()
+
+	env := &gatewayEnv{zp: zalopaytest.NewServer(2553, "test-key1", "test-key2")}
+	t.Cleanup(env.zp.Close)
+
+	cb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ev, err := env.gw.ParseCallback(r.Context(), r)
+		env.mu.Lock()
+		if err != nil {
+			env.errs = append(env.errs, err)
+		} else {
+			env.events = append(env.events, ev)
+		}
+		env.mu.Unlock()
+		env.gw.AcknowledgeCallback(w, err)
+	}))
+	t.Cleanup(cb.Close)
+
+	gw, err := env.zp.Gateway(cb.URL)
+	require.NoError(t, err)
+	env.gw = gw
+	return env
+}
+
+// createOrder opens a payment for orderCode and returns its app_trans_id.
+func (e *gatewayEnv) createOrder(t *testing.T, orderCode string, amount float64) string {
+	t.Helper()
+
+	res, err := e.gw.ProcessPayment(context.Background(), &payment.ProcessPaymentRequest{
+		OrderCode: orderCode,
+		Amount:    amount,
+		UserId:    "user-1",
+	}, &order.OrderData{Code: orderCode, UserId: "user-1"})
+	require.NoError(t, err)
+	return res.Payment.Id
+}
+
+func (e *gatewayEnv) status(t *testing.T, appTransID string) models.PaymentStatus {
+	t.Helper()
+
+	st, err := e.gw.QueryPaymentStatus(context.Background(), models.Payment{GatewayReference: appTransID})
+	require.NoError(t, err)
+	return st
+}
+
+func TestParseCallbackVerifiesMac(t *testing.T) {
+	env := newGatewayEnv(t)
+	appTransID := env.createOrder(t, "ORD-GW-1", 50000)
+
+	require.NoError(t, env.zp.Pay(appTransID))
+	require.Len(t, env.events, 1)
+	require.Equal(t, "ORD-GW-1", env.events[0].OrderCode)
+	require.Equal(t, appTransID, env.events[0].GatewayReference)
+	require.Equal(t, 50000.0, env.events[0].Amount)
+	require.Equal(t, models.PaymentStatusCompleted, env.events[0].Status)
+
+	// A callback signed with another key2 is rejected, and ZaloPay is told
+	// not to retry it.
+	env.zp.Key2 = "wrong-key2"
+	require.ErrorIs(t, env.zp.Redeliver(appTransID), zalopaytest.ErrNotAcknowledged)
+	require.Len(t, env.events, 1)
+	require.Len(t, env.errs, 1)
+	require.ErrorIs(t, env.errs[0], bankTf.ErrInvalidSignature)
+	callbacks := env.zp.Callbacks()
+	require.Equal(t, 1, callbacks[len(callbacks)-1].Attempt)
+}
+
+func TestRequestsWithWrongMacAreRejected(t *testing.T) {
+	env := newGatewayEnv(t)
+	appTransID := env.createOrder(t, "ORD-GW-2", 50000)
+
+	env.zp.Key1 = "wrong-key1"
+	_, err := env.gw.ProcessPayment(context.Background(), &payment.ProcessPaymentRequest{OrderCode: "ORD-GW-3", Amount: 50000},
+		&order.OrderData{Code: "ORD-GW-3"})
+	require.Error(t, err)
+
+	// A rejected query says nothing about the order.
+	_, err = env.gw.QueryPaymentStatus(context.Background(), models.Payment{GatewayReference: appTransID})
+	require.ErrorIs(t, err, bankTf.ErrGatewayUnavailable)
+}
+
+func TestProcessPaymentRejectsLongAppTransID(t *testing.T) {
+	env := newGatewayEnv(t)
+
+	// yymmdd_ and a 33 character order code are ZaloPay's 40 characters.
+	fits := strings.Repeat("A", 33)
+	env.createOrder(t, fits, 50000)
+
+	tooLong := fits + "B"
+	_, err := env.gw.ProcessPayment(context.Background(), &payment.ProcessPaymentRequest{OrderCode: tooLong, Amount: 50000},
+		&order.OrderData{Code: tooLong})
+	require.ErrorIs(t, err, bankTf.ErrInvalidInput)
+}
+
+func TestQueryPaymentStatus(t *testing.T) {
+	env := newGatewayEnv(t)
+	paid := env.createOrder(t, "ORD-GW-4", 50000)
+	failed := env.createOrder(t, "ORD-GW-5", 50000)
+
+	require.Equal(t, models.PaymentStatusPending, env.status(t, paid))
+
+	require.NoError(t, env.zp.Pay(paid))
+	require.NoError(t, env.zp.Fail(failed))
+	require.Equal(t, models.PaymentStatusCompleted, env.status(t, paid))
+	require.Equal(t, models.PaymentStatusFailed, env.status(t, failed))
+
+	_, err := env.gw.QueryPaymentStatus(context.Background(), models.Payment{GatewayReference: "240101_ORD-UNKNOWN"})
+	require.ErrorIs(t, err, bankTf.ErrGatewayUnavailable)
+}
+
+func TestRefundPayment(t *testing.T) {
+	env := newGatewayEnv(t)
+	appTransID := env.createOrder(t, "ORD-GW-6", 50000)
+	p := models.Payment{OrderCode: "ORD-GW-6", GatewayReference: appTransID}
+	rf := models.PaymentRefund{ID: "rf1", Amount: 20000, CreatedAt: time.Now()}
+
+	_, err := env.gw.RefundPayment(context.Background(), p, rf)
+	require.Error(t, err, "unpaid orders cannot be refunded")
+
+	require.NoError(t, env.zp.Pay(appTransID))
+	refundID, err := env.gw.RefundPayment(context.Background(), p, rf)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%s_2553_rf1", rf.CreatedAt.Format("060102")), refundID)
+
+	refunds := env.zp.Refunds()
+	require.Len(t, refunds, 1)
+	require.Equal(t, refundID, refunds[0].MRefundID)
+	require.EqualValues(t, 20000, refunds[0].Amount)
+	require.Equal(t, "Refund ORD-GW-6", refunds[0].Description)
+
+	// A retry of the refund gets the same result and refunds nothing more;
+	// another refund reusing its ID is rejected.
+	retried, err := env.gw.RefundPayment(context.Background(), p, rf)
+	require.NoError(t, err)
+	require.Equal(t, refundID, retried)
+	_, err = env.gw.RefundPayment(context.Background(), p, models.PaymentRefund{ID: "rf1", Amount: 10000, CreatedAt: rf.CreatedAt})
+	require.Error(t, err)
+	require.Len(t, env.zp.Refunds(), 1)
+
+	_, err = env.gw.RefundPayment(context.Background(), p, models.PaymentRefund{ID: "rf2", Amount: 40000, Reason: "too much", CreatedAt: time.Now()})
+	require.Error(t, err)
+	require.Len(t, env.zp.Refunds(), 1)
+}
+
+func TestListBanks(t *testing.T) {
+	env := newGatewayEnv(t)
+
+	banks, err := env.gw.ListBanks(context.Background())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []bankTf.Bank{
+		{Code: "zalopayapp", Name: "ZaloPay", Channel: zalopay.ChannelZaloPayApp, MinAmount: 1000, MaxAmount: 50000000, DisplayOrder: 0},
+		{Code: "CC", Name: "Visa/Master/JCB", Channel: zalopay.ChannelCC, MinAmount: 1000, MaxAmount: 50000000, DisplayOrder: 1},
+		{Code: "VTB", Name: "VietinBank", Channel: zalopay.ChannelATM, MinAmount: 10000, MaxAmount: 50000000, DisplayOrder: 2},
+		{Code: "VCB", Name: "Vietcombank", Channel: zalopay.ChannelATM, MinAmount: 10000, MaxAmount: 50000000, DisplayOrder: 3},
+	}, banks)
+
+	// A request ZaloPay rejects is an error, not an empty list.
+	gw, err := env.zp.Gateway("")
+	require.NoError(t, err)
+	env.zp.Key1 = "wrong-key1"
+	_, err = gw.ListBanks(context.Background())
+	require.Error(t, err)
+}
+
+// macRecorder passes requests on and keeps the mac each one was sent with,
+// by path.
+type macRecorder struct {
+	mu   sync.Mutex
+	macs map[string]string
+}
+
+func (r *macRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	var mac string
+	if form, err := url.ParseQuery(string(body)); err == nil && form.Has("mac") {
+		mac = form.Get("mac")
+	} else {
+		var fields struct {
+			Mac string `json:"mac"`
+		}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil, err
+		}
+		mac = fields.Mac
+	}
+
+	r.mu.Lock()
+	r.macs[req.URL.Path] = mac
+	r.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func hmacSHA256(key, data string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// TestRequestMacs pins the clock and checks each request's MAC against the
+// fields ZaloPay signs, in its order. The contract fixture scrubs MACs, so
+// this is what catches a change in how requests are signed.
+func TestRequestMacs(t *testing.T) {
+	env := newGatewayEnv(t)
+	rec := &macRecorder{macs: map[string]string{}}
+	env.gw.HTTP = gatewayhttp.New(string(models.GatewayTypeZalopay), nil, gatewayhttp.Settings{Transport: rec})
+	now := time.Date(2024, 1, 2, 10, 30, 0, 0, time.FixedZone("ICT", 7*60*60))
+	env.gw.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	res, err := env.gw.ProcessPayment(ctx, &payment.ProcessPaymentRequest{
+		OrderCode: "ORD-MAC-1",
+		Amount:    50000,
+		UserId:    "user-1",
+	}, &order.OrderData{
+		Code:   "ORD-MAC-1",
+		UserId: "user-1",
+		Items: []*order.OrderItem{
+			{ProductId: "p1", ProductName: "Áo thun", ProductPrice: 25000, Quantity: 2},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "240102_ORD-MAC-1", res.Payment.Id)
+	require.Equal(t, hmacSHA256("test-key1", `2553|240102_ORD-MAC-1|user-1|50000|1704166200000|`+
+		`{"redirecturl":"http://localhost:3000/payment/success?bookingCode=ORD-MAC-1"}|`+
+		`[{"itemid":"p1","itemname":"Áo thun","itemprice":25000,"itemquantity":2}]`), rec.macs[zalopaytest.CreatePath])
+
+	require.NoError(t, env.zp.Pay(res.Payment.Id))
+	_, err = env.gw.RefundPayment(ctx, models.Payment{OrderCode: "ORD-MAC-1", GatewayReference: res.Payment.Id},
+		models.PaymentRefund{ID: "rf1", Amount: 20000, CreatedAt: now})
+	require.NoError(t, err)
+	require.Equal(t, hmacSHA256("test-key1", "2553|240102_ORD-MAC-1|test-key1"), rec.macs[zalopaytest.QueryPath])
+	require.Equal(t, hmacSHA256("test-key1", "2553|240000000001|20000|Refund ORD-MAC-1|1704166200000"), rec.macs[zalopaytest.RefundPath])
+
+	_, err = env.gw.ListBanks(ctx)
+	require.NoError(t, err)
+	require.Equal(t, hmacSHA256("test-key1", "2553|1704166200000"), rec.macs[zalopaytest.BankListPath])
+}
@@ -0,0 +1,206 @@
+package zalopaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+)
+
+// Callback return codes, as in the zalopay package.
+const (
+	callbackCodeSuccess = 1
+	callbackCodeRetry   = 2
+)
+
+var (
+	ErrOrderNotFound   = errors.New("zalopaytest: order not found")
+	ErrOrderNotPaying  = errors.New("zalopaytest: order is not pending")
+	ErrNotAcknowledged = errors.New("zalopaytest: callback not acknowledged")
+)
+
+// Pay marks an order paid and delivers its callback, retrying like ZaloPay:
+// the callback is resent while the merchant answers return_code 2, a non-200
+// status or nothing at all, up to CallbackAttempts times. It returns
+// ErrNotAcknowledged when no attempt got return_code 1.
+func (s *Server) Pay(appTransID string) error {
+	s.mu.Lock()
+	o, ok := s.orders[appTransID]
+	if !ok {
+		s.mu.Unlock()
+		return ErrOrderNotFound
+	}
+	if o.Status != OrderPending {
+		s.mu.Unlock()
+		return ErrOrderNotPaying
+	}
+	s.nextZpID++
+	o.Status = OrderPaid
+	o.ZpTransID = s.nextZpID
+	order := *o
+	s.mu.Unlock()
+
+	return s.deliver(order)
+}
+
+// Fail marks an order failed. ZaloPay sends no callback for failures, so
+// the merchant only learns about it by querying.
+func (s *Server) Fail(appTransID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[appTransID]
+	if !ok {
+		return ErrOrderNotFound
+	}
+	if o.Status != OrderPending {
+		return ErrOrderNotPaying
+	}
+	o.Status = OrderFailed
+	return nil
+}
+
+// Redeliver sends the callback of a paid order again, as ZaloPay sometimes
+// does after the merchant already acknowledged it.
+func (s *Server) Redeliver(appTransID string) error {
+	s.mu.Lock()
+	o, ok := s.orders[appTransID]
+	if !ok {
+		s.mu.Unlock()
+		return ErrOrderNotFound
+	}
+	if o.Status != OrderPaid {
+		s.mu.Unlock()
+		return ErrOrderNotPaying
+	}
+	order := *o
+	s.mu.Unlock()
+
+	return s.deliver(order)
+}
+
+func (s *Server) deliver(o Order) error {
+	body, err := s.callbackBody(o)
+	if err != nil {
+		return err
+	}
+
+	url := s.CallbackURL
+	if url == "" {
+		url = o.CallbackURL
+	}
+
+	attempts := max(s.CallbackAttempts, 1)
+	for i := 1; i <= attempts; i++ {
+		if i > 1 {
+			time.Sleep(s.CallbackInterval)
+		}
+
+		attempt := s.post(url, body)
+		attempt.AppTransID = o.AppTransID
+		attempt.Attempt = i
+
+		s.mu.Lock()
+		s.callbacks = append(s.callbacks, attempt)
+		s.mu.Unlock()
+
+		if attempt.ReturnCode == callbackCodeSuccess {
+			return nil
+		}
+		// Any code but retry is a final rejection by the merchant.
+		if attempt.Err == nil && attempt.StatusCode == http.StatusOK && attempt.ReturnCode != callbackCodeRetry {
+			break
+		}
+	}
+
+	return ErrNotAcknowledged
+}
+
+func (s *Server) callbackBody(o Order) ([]byte, error) {
+	data, err := json.Marshal(callbackData{
+		AppID:          s.AppID,
+		AppTransID:     o.AppTransID,
+		AppTime:        o.AppTime,
+		AppUser:        o.AppUser,
+		Amount:         o.Amount,
+		EmbedData:      o.EmbedData,
+		Item:           o.Item,
+		ZpTransID:      o.ZpTransID,
+		ServerTime:     time.Now().UnixMilli(),
+		Channel:        38,
+		MerchantUserID: o.AppUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(callbackBody{
+		Data: string(data),
+		Mac:  keyring.HMAC(s.Key2, string(data)),
+		Type: 1,
+	})
+}
+
+func (s *Server) post(url string, body []byte) CallbackAttempt {
+	resp, err := s.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return CallbackAttempt{Err: err}
+	}
+	defer resp.Body.Close()
+
+	attempt := CallbackAttempt{StatusCode: resp.StatusCode}
+	var ack struct {
+		ReturnCode    int    `json:"return_code"`
+		ReturnMessage string `json:"return_message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ack); err != nil {
+		attempt.Err = fmt.Errorf("decode acknowledgement: %w", err)
+		return attempt
+	}
+	attempt.ReturnCode = ack.ReturnCode
+	attempt.ReturnMessage = ack.ReturnMessage
+	return attempt
+}
+
+// Order returns a copy of a stored order.
+func (s *Server) Order(appTransID string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[appTransID]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// OrderByCode finds the order created for one of our order codes, whose
+// app_trans_id is "yymmdd_<order code>".
+func (s *Server) OrderByCode(orderCode string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range s.orders {
+		if len(o.AppTransID) > 7 && o.AppTransID[7:] == orderCode {
+			return *o, true
+		}
+	}
+	return Order{}, false
+}
+
+func (s *Server) Refunds() []Refund {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Refund(nil), s.refunds...)
+}
+
+// Callbacks lists every delivery attempt so far, oldest first.
+func (s *Server) Callbacks() []CallbackAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CallbackAttempt(nil), s.callbacks...)
+}
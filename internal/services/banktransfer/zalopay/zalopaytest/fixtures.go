@@ -0,0 +1,35 @@
+package zalopaytest
+
+import (
+	"regexp"
+
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp"
+	"github.com/vogiaan1904/payment-svc/internal/gatewayhttp/replay"
+	"github.com/vogiaan1904/payment-svc/internal/models"
+	"github.com/vogiaan1904/payment-svc/internal/services/banktransfer/zalopay"
+)
+
+// Scrubber masks the one-off payment links and tokens ZaloPay hands out,
+// and the times, date prefixes of app_trans_id and MACs that change with
+// the clock, so fixtures are recorded against the sandbox as it is. Field
+// order and structure of requests are still compared; MACs are tested on
+// their own with a pinned clock. Pass the app's keys as secrets when
+// recording with keys of your own.
+func Scrubber(secrets ...string) replay.Scrubber {
+	return replay.Scrubber{
+		Fields: []string{
+			"order_url", "order_token", "zp_trans_token", "qr_code", "deeplink",
+			"app_time", "timestamp", "reqtime", "server_time", "mac",
+		},
+		Secrets:      secrets,
+		Replacements: []replay.Replacement{{Pattern: transDate, With: "yymmdd_"}},
+	}
+}
+
+// transDate matches the yymmdd_ prefix of app_trans_id and m_refund_id.
+var transDate = regexp.MustCompile(`\b\d{6}_`)
+
+// UseCassette sends the gateway's calls through c.
+func UseCassette(gw *zalopay.ZalopayGateway, c *replay.Cassette) {
+	gw.HTTP = gatewayhttp.New(string(models.GatewayTypeZalopay), nil, gatewayhttp.Settings{Transport: c})
+}
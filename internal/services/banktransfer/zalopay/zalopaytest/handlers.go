@@ -0,0 +1,239 @@
+package zalopaytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+)
+
+// Sub return codes the fake answers with, taken from ZaloPay's docs.
+const (
+	subCodeInvalidMac    = -402
+	subCodeDuplicate     = -68
+	subCodeNotFound      = -92
+	subCodeNotPaid       = -101
+	subCodeInvalidRefund = -13
+)
+
+type response map[string]any
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	mac := fmt.Sprintf("%d|%s|%s|%d|%d|%s|%s",
+		req.AppID, req.AppTransID, req.AppUser, req.Amount, req.AppTime, req.EmbedData, req.Item)
+	if !s.validMac(req.AppID, mac, req.Mac) {
+		writeError(w, subCodeInvalidMac, "invalid mac")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orders[req.AppTransID]; ok {
+		writeError(w, subCodeDuplicate, "duplicate app_trans_id")
+		return
+	}
+
+	s.orders[req.AppTransID] = &Order{
+		AppTransID:  req.AppTransID,
+		AppUser:     req.AppUser,
+		AppTime:     req.AppTime,
+		Amount:      req.Amount,
+		EmbedData:   req.EmbedData,
+		Item:        req.Item,
+		Description: req.Description,
+		BankCode:    req.BankCode,
+		CallbackURL: req.CallbackURL,
+		Status:      OrderPending,
+		CreatedAt:   time.Now(),
+	}
+
+	token := "tok_" + req.AppTransID
+	writeJSON(w, response{
+		"return_code":        1,
+		"return_message":     "Giao dịch thành công",
+		"sub_return_code":    1,
+		"sub_return_message": "Giao dịch thành công",
+		"order_url":          s.srv.URL + PayPath + token,
+		"order_token":        token,
+		"zp_trans_token":     token,
+		"qr_code":            "zalopay://qr/" + token,
+		"deeplink":           "zalopay://app/pay?order=" + token,
+	})
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if !s.validMac(req.AppID, fmt.Sprintf("%d|%s|%s", req.AppID, req.AppTransID, s.Key1), req.Mac) {
+		writeError(w, subCodeInvalidMac, "invalid mac")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[req.AppTransID]
+	if !ok {
+		writeError(w, subCodeNotFound, "order not found")
+		return
+	}
+
+	switch o.Status {
+	case OrderPaid:
+		writeJSON(w, response{
+			"return_code":    1,
+			"return_message": "Giao dịch thành công",
+			"is_processing":  false,
+			"amount":         o.Amount,
+			"zp_trans_id":    o.ZpTransID,
+		})
+	case OrderFailed:
+		writeJSON(w, response{
+			"return_code":    2,
+			"return_message": "Giao dịch thất bại",
+			"is_processing":  false,
+			"amount":         o.Amount,
+		})
+	default:
+		writeJSON(w, response{
+			"return_code":    3,
+			"return_message": "Giao dịch chưa thực hiện",
+			"is_processing":  true,
+			"amount":         o.Amount,
+		})
+	}
+}
+
+func (s *Server) handleRefund(w http.ResponseWriter, r *http.Request) {
+	var req refundRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	mac := fmt.Sprintf("%d|%s|%d|%s|%d", req.AppID, req.ZpTransID, req.Amount, req.Description, req.Timestamp)
+	if !s.validMac(req.AppID, mac, req.Mac) {
+		writeError(w, subCodeInvalidMac, "invalid mac")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// ZaloPay refunds an m_refund_id once: a retry of the same refund gets
+	// its result again, and another refund reusing the ID is rejected.
+	for _, refund := range s.refunds {
+		if refund.MRefundID != req.MRefundID {
+			continue
+		}
+		if refund.ZpTransID != req.ZpTransID || refund.Amount != req.Amount {
+			writeError(w, subCodeDuplicate, "duplicate m_refund_id")
+			return
+		}
+		writeRefund(w, refund)
+		return
+	}
+
+	var o *Order
+	for _, candidate := range s.orders {
+		if candidate.Status == OrderPaid && strconv.FormatInt(candidate.ZpTransID, 10) == req.ZpTransID {
+			o = candidate
+			break
+		}
+	}
+	if o == nil {
+		writeError(w, subCodeNotPaid, "transaction not found or not paid")
+		return
+	}
+	if req.Amount <= 0 || o.Refunded+req.Amount > o.Amount {
+		writeError(w, subCodeInvalidRefund, "invalid refund amount")
+		return
+	}
+
+	o.Refunded += req.Amount
+	refund := Refund{
+		MRefundID:   req.MRefundID,
+		ZpTransID:   req.ZpTransID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		RefundID:    s.nextZpID + int64(len(s.refunds)) + 1,
+	}
+	s.refunds = append(s.refunds, refund)
+	writeRefund(w, refund)
+}
+
+func writeRefund(w http.ResponseWriter, refund Refund) {
+	writeJSON(w, response{
+		"return_code":        1,
+		"return_message":     "Giao dịch thành công",
+		"sub_return_code":    1,
+		"sub_return_message": "Giao dịch thành công",
+		"refund_id":          refund.RefundID,
+	})
+}
+
+func (s *Server) handleBankList(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	appID, _ := strconv.Atoi(r.PostForm.Get("appid"))
+	if !s.validMac(appID, r.PostForm.Get("appid")+"|"+r.PostForm.Get("reqtime"), r.PostForm.Get("mac")) {
+		writeJSON(w, response{"returncode": -1, "returnmessage": "invalid mac"})
+		return
+	}
+
+	s.mu.Lock()
+	banks := make(map[string][]Bank)
+	for _, b := range s.Banks {
+		pmc := strconv.Itoa(b.PmcID)
+		banks[pmc] = append(banks[pmc], b)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, response{"returncode": 1, "returnmessage": "", "banks": banks})
+}
+
+// validMac checks a key1 signature the way ZaloPay does; the app ID has to
+// match too, as a request signed for another app is rejected the same way.
+func (s *Server) validMac(appID int, data string, mac string) bool {
+	return appID == s.AppID && keyring.HMAC(s.Key1, data) == mac
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, subCode int, msg string) {
+	writeJSON(w, response{
+		"return_code":        2,
+		"return_message":     "Giao dịch thất bại",
+		"sub_return_code":    subCode,
+		"sub_return_message": msg,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,64 @@
+package zalopaytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vogiaan1904/payment-svc/internal/keyring"
+)
+
+// Redirect status codes ZaloPay puts on the return URL; failures come with
+// a negative code, -49 being a customer who cancelled.
+const (
+	RedirectStatusSuccess   = 1
+	RedirectStatusCancelled = -49
+)
+
+// RedirectURL is where ZaloPay sends the customer after the order's
+// payment page: the redirecturl from its embed_data with ZaloPay's signed
+// query parameters appended. status is RedirectStatusSuccess for paid
+// orders and RedirectStatusCancelled otherwise.
+func (s *Server) RedirectURL(appTransID string) (string, error) {
+	s.mu.Lock()
+	o, ok := s.orders[appTransID]
+	if !ok {
+		s.mu.Unlock()
+		return "", ErrOrderNotFound
+	}
+	order := *o
+	s.mu.Unlock()
+
+	var ed struct {
+		RedirectURL string `json:"redirecturl"`
+	}
+	if err := json.Unmarshal([]byte(order.EmbedData), &ed); err != nil {
+		return "", fmt.Errorf("zalopaytest: invalid embed_data: %w", err)
+	}
+	u, err := url.Parse(ed.RedirectURL)
+	if err != nil {
+		return "", fmt.Errorf("zalopaytest: invalid redirecturl: %w", err)
+	}
+
+	status := RedirectStatusCancelled
+	if order.Status == OrderPaid {
+		status = RedirectStatusSuccess
+	}
+
+	q := u.Query()
+	q.Set("appid", strconv.Itoa(s.AppID))
+	q.Set("apptransid", order.AppTransID)
+	q.Set("pmcid", "38")
+	q.Set("bankcode", order.BankCode)
+	q.Set("amount", strconv.FormatInt(order.Amount, 10))
+	q.Set("discountamount", "0")
+	q.Set("status", strconv.Itoa(status))
+	q.Set("checksum", keyring.HMAC(s.Key2, strings.Join([]string{
+		q.Get("appid"), q.Get("apptransid"), q.Get("pmcid"), q.Get("bankcode"),
+		q.Get("amount"), q.Get("discountamount"), q.Get("status"),
+	}, "|")))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
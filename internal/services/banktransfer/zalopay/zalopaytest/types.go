@@ -0,0 +1,119 @@
+package zalopaytest
+
+import "time"
+
+type OrderStatus int
+
+const (
+	OrderPending OrderStatus = iota
+	OrderPaid
+	OrderFailed
+)
+
+// Order is what the fake stored from a create request.
+type Order struct {
+	AppTransID  string
+	AppUser     string
+	AppTime     int64
+	Amount      int64
+	EmbedData   string
+	Item        string
+	Description string
+	BankCode    string
+	CallbackURL string
+	Status      OrderStatus
+	ZpTransID   int64
+	Refunded    int64
+	CreatedAt   time.Time
+}
+
+type Refund struct {
+	MRefundID   string
+	ZpTransID   string
+	Amount      int64
+	Description string
+	RefundID    int64
+}
+
+// CallbackAttempt is one delivery of a callback and the merchant's answer.
+// ReturnCode is 0 when no valid acknowledgement came back.
+type CallbackAttempt struct {
+	AppTransID    string
+	Attempt       int
+	StatusCode    int
+	ReturnCode    int
+	ReturnMessage string
+	Err           error
+}
+
+type Bank struct {
+	BankCode     string  `json:"bankcode"`
+	Name         string  `json:"name"`
+	DisplayOrder int     `json:"displayorder"`
+	PmcID        int     `json:"pmcid"`
+	MinAmount    float64 `json:"minamount"`
+	MaxAmount    float64 `json:"maxamount"`
+}
+
+// DefaultBanks mirrors a small sandbox merchant: the ZaloPay app, cards and
+// two ATM banks.
+func DefaultBanks() []Bank {
+	return []Bank{
+		{BankCode: "zalopayapp", Name: "ZaloPay", DisplayOrder: 0, PmcID: 38, MinAmount: 1000, MaxAmount: 50000000},
+		{BankCode: "CC", Name: "Visa/Master/JCB", DisplayOrder: 1, PmcID: 36, MinAmount: 1000, MaxAmount: 50000000},
+		{BankCode: "VTB", Name: "VietinBank", DisplayOrder: 2, PmcID: 39, MinAmount: 10000, MaxAmount: 50000000},
+		{BankCode: "VCB", Name: "Vietcombank", DisplayOrder: 3, PmcID: 39, MinAmount: 10000, MaxAmount: 50000000},
+	}
+}
+
+type createRequest struct {
+	AppID       int    `json:"app_id"`
+	AppUser     string `json:"app_user"`
+	AppTime     int64  `json:"app_time"`
+	Amount      int64  `json:"amount"`
+	AppTransID  string `json:"app_trans_id"`
+	EmbedData   string `json:"embed_data"`
+	Description string `json:"description"`
+	BankCode    string `json:"bank_code"`
+	CallbackURL string `json:"callback_url"`
+	Item        string `json:"item"`
+	Mac         string `json:"mac"`
+}
+
+type queryRequest struct {
+	AppID      int    `json:"app_id"`
+	AppTransID string `json:"app_trans_id"`
+	Mac        string `json:"mac"`
+}
+
+type refundRequest struct {
+	AppID       int    `json:"app_id"`
+	MRefundID   string `json:"m_refund_id"`
+	ZpTransID   string `json:"zp_trans_id"`
+	Amount      int64  `json:"amount"`
+	Timestamp   int64  `json:"timestamp"`
+	Description string `json:"description"`
+	Mac         string `json:"mac"`
+}
+
+type callbackData struct {
+	AppID          int    `json:"app_id"`
+	AppTransID     string `json:"app_trans_id"`
+	AppTime        int64  `json:"app_time"`
+	AppUser        string `json:"app_user"`
+	Amount         int64  `json:"amount"`
+	EmbedData      string `json:"embed_data"`
+	Item           string `json:"item"`
+	ZpTransID      int64  `json:"zp_trans_id"`
+	ServerTime     int64  `json:"server_time"`
+	Channel        int    `json:"channel"`
+	MerchantUserID string `json:"merchant_user_id"`
+	UserFeeAmount  int64  `json:"user_fee_amount"`
+	DiscountAmount int64  `json:"discount_amount"`
+}
+
+type callbackBody struct {
+	Data string `json:"data"`
+	Mac  string `json:"mac"`
+	Type int    `json:"type"`
+}
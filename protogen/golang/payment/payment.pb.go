@@ -22,6 +22,114 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PaymentStatus int32
+
+const (
+	PaymentStatus_PAYMENT_STATUS_UNSPECIFIED PaymentStatus = 0
+	PaymentStatus_PAYMENT_STATUS_PENDING     PaymentStatus = 1
+	PaymentStatus_PAYMENT_STATUS_COMPLETED   PaymentStatus = 2
+	PaymentStatus_PAYMENT_STATUS_FAILED      PaymentStatus = 3
+	PaymentStatus_PAYMENT_STATUS_CANCELLED   PaymentStatus = 4
+	PaymentStatus_PAYMENT_STATUS_REFUNDED    PaymentStatus = 5
+)
+
+// Enum value maps for PaymentStatus.
+var (
+	PaymentStatus_name = map[int32]string{
+		0: "PAYMENT_STATUS_UNSPECIFIED",
+		1: "PAYMENT_STATUS_PENDING",
+		2: "PAYMENT_STATUS_COMPLETED",
+		3: "PAYMENT_STATUS_FAILED",
+		4: "PAYMENT_STATUS_CANCELLED",
+		5: "PAYMENT_STATUS_REFUNDED",
+	}
+	PaymentStatus_value = map[string]int32{
+		"PAYMENT_STATUS_UNSPECIFIED": 0,
+		"PAYMENT_STATUS_PENDING":     1,
+		"PAYMENT_STATUS_COMPLETED":   2,
+		"PAYMENT_STATUS_FAILED":      3,
+		"PAYMENT_STATUS_CANCELLED":   4,
+		"PAYMENT_STATUS_REFUNDED":    5,
+	}
+)
+
+func (x PaymentStatus) Enum() *PaymentStatus {
+	p := new(PaymentStatus)
+	*p = x
+	return p
+}
+
+func (x PaymentStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PaymentStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[0].Descriptor()
+}
+
+func (PaymentStatus) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[0]
+}
+
+func (x PaymentStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PaymentStatus.Descriptor instead.
+func (PaymentStatus) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{0}
+}
+
+// How the customer completes the payment with the provider.
+type PaymentMode int32
+
+const (
+	PaymentMode_PAYMENT_MODE_REDIRECT PaymentMode = 0 // web page at payment_url
+	PaymentMode_PAYMENT_MODE_QR       PaymentMode = 1 // QR code scanned from the provider's app
+	PaymentMode_PAYMENT_MODE_APP      PaymentMode = 2 // app-to-app through the provider's SDK
+)
+
+// Enum value maps for PaymentMode.
+var (
+	PaymentMode_name = map[int32]string{
+		0: "PAYMENT_MODE_REDIRECT",
+		1: "PAYMENT_MODE_QR",
+		2: "PAYMENT_MODE_APP",
+	}
+	PaymentMode_value = map[string]int32{
+		"PAYMENT_MODE_REDIRECT": 0,
+		"PAYMENT_MODE_QR":       1,
+		"PAYMENT_MODE_APP":      2,
+	}
+)
+
+func (x PaymentMode) Enum() *PaymentMode {
+	p := new(PaymentMode)
+	*p = x
+	return p
+}
+
+func (x PaymentMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PaymentMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[1].Descriptor()
+}
+
+func (PaymentMode) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[1]
+}
+
+func (x PaymentMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PaymentMode.Descriptor instead.
+func (PaymentMode) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{1}
+}
+
 // Bank transfer method
 type PaymentData struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -32,6 +140,7 @@ type PaymentData struct {
 	Provider        string                 `protobuf:"bytes,5,opt,name=provider,proto3" json:"provider,omitempty"`
 	ProviderDetails string                 `protobuf:"bytes,6,opt,name=provider_details,json=providerDetails,proto3" json:"provider_details,omitempty"`
 	Metadata        map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status          PaymentStatus          `protobuf:"varint,8,opt,name=status,proto3,enum=payment.PaymentStatus" json:"status,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -115,14 +224,24 @@ func (x *PaymentData) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *PaymentData) GetStatus() PaymentStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
 type ProcessPaymentRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	OrderCode       string                 `protobuf:"bytes,1,opt,name=order_code,json=orderCode,proto3" json:"order_code,omitempty"`
 	UserId          string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Amount          float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	Provider        string                 `protobuf:"bytes,4,opt,name=provider,proto3" json:"provider,omitempty"`
-	ProviderDetails string                 `protobuf:"bytes,5,opt,name=provider_details,json=providerDetails,proto3" json:"provider_details,omitempty"`
+	Provider        string                 `protobuf:"bytes,4,opt,name=provider,proto3" json:"provider,omitempty"`                                      // empty lets the router pick one
+	ProviderDetails string                 `protobuf:"bytes,5,opt,name=provider_details,json=providerDetails,proto3" json:"provider_details,omitempty"` // provider channel, e.g. zalopayapp, ATM, CC or a bank code
 	Metadata        map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Currency        string                 `protobuf:"bytes,7,opt,name=currency,proto3" json:"currency,omitempty"` // defaults to VND
+	UserSegment     string                 `protobuf:"bytes,8,opt,name=user_segment,json=userSegment,proto3" json:"user_segment,omitempty"`
+	Mode            PaymentMode            `protobuf:"varint,9,opt,name=mode,proto3,enum=payment.PaymentMode" json:"mode,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -199,10 +318,37 @@ func (x *ProcessPaymentRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *ProcessPaymentRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ProcessPaymentRequest) GetUserSegment() string {
+	if x != nil {
+		return x.UserSegment
+	}
+	return ""
+}
+
+func (x *ProcessPaymentRequest) GetMode() PaymentMode {
+	if x != nil {
+		return x.Mode
+	}
+	return PaymentMode_PAYMENT_MODE_REDIRECT
+}
+
 type ProcessPaymentResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Payment       *PaymentData           `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
-	PaymentUrl    string                 `protobuf:"bytes,2,opt,name=payment_url,json=paymentUrl,proto3" json:"payment_url,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Payment    *PaymentData           `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	PaymentUrl string                 `protobuf:"bytes,2,opt,name=payment_url,json=paymentUrl,proto3" json:"payment_url,omitempty"` // set whenever the provider has a web page, whatever the mode
+	Mode       PaymentMode            `protobuf:"varint,3,opt,name=mode,proto3,enum=payment.PaymentMode" json:"mode,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ProcessPaymentResponse_Qr
+	//	*ProcessPaymentResponse_App
+	Payload       isProcessPaymentResponse_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -251,6 +397,150 @@ func (x *ProcessPaymentResponse) GetPaymentUrl() string {
 	return ""
 }
 
+func (x *ProcessPaymentResponse) GetMode() PaymentMode {
+	if x != nil {
+		return x.Mode
+	}
+	return PaymentMode_PAYMENT_MODE_REDIRECT
+}
+
+func (x *ProcessPaymentResponse) GetPayload() isProcessPaymentResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ProcessPaymentResponse) GetQr() *QRPayload {
+	if x != nil {
+		if x, ok := x.Payload.(*ProcessPaymentResponse_Qr); ok {
+			return x.Qr
+		}
+	}
+	return nil
+}
+
+func (x *ProcessPaymentResponse) GetApp() *AppPayload {
+	if x != nil {
+		if x, ok := x.Payload.(*ProcessPaymentResponse_App); ok {
+			return x.App
+		}
+	}
+	return nil
+}
+
+type isProcessPaymentResponse_Payload interface {
+	isProcessPaymentResponse_Payload()
+}
+
+type ProcessPaymentResponse_Qr struct {
+	Qr *QRPayload `protobuf:"bytes,4,opt,name=qr,proto3,oneof"`
+}
+
+type ProcessPaymentResponse_App struct {
+	App *AppPayload `protobuf:"bytes,5,opt,name=app,proto3,oneof"`
+}
+
+func (*ProcessPaymentResponse_Qr) isProcessPaymentResponse_Payload() {}
+
+func (*ProcessPaymentResponse_App) isProcessPaymentResponse_Payload() {}
+
+type QRPayload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QrCode        string                 `protobuf:"bytes,1,opt,name=qr_code,json=qrCode,proto3" json:"qr_code,omitempty"` // content to render as a QR code
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QRPayload) Reset() {
+	*x = QRPayload{}
+	mi := &file_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QRPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QRPayload) ProtoMessage() {}
+
+func (x *QRPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QRPayload.ProtoReflect.Descriptor instead.
+func (*QRPayload) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *QRPayload) GetQrCode() string {
+	if x != nil {
+		return x.QrCode
+	}
+	return ""
+}
+
+type AppPayload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // passed to the provider SDK
+	Deeplink      string                 `protobuf:"bytes,2,opt,name=deeplink,proto3" json:"deeplink,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppPayload) Reset() {
+	*x = AppPayload{}
+	mi := &file_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppPayload) ProtoMessage() {}
+
+func (x *AppPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppPayload.ProtoReflect.Descriptor instead.
+func (*AppPayload) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AppPayload) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *AppPayload) GetDeeplink() string {
+	if x != nil {
+		return x.Deeplink
+	}
+	return ""
+}
+
 type CancelPaymentRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to PaymentIdentifier:
@@ -265,7 +555,7 @@ type CancelPaymentRequest struct {
 
 func (x *CancelPaymentRequest) Reset() {
 	*x = CancelPaymentRequest{}
-	mi := &file_payment_proto_msgTypes[3]
+	mi := &file_payment_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -277,7 +567,7 @@ func (x *CancelPaymentRequest) String() string {
 func (*CancelPaymentRequest) ProtoMessage() {}
 
 func (x *CancelPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[3]
+	mi := &file_payment_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -290,7 +580,7 @@ func (x *CancelPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelPaymentRequest.ProtoReflect.Descriptor instead.
 func (*CancelPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{3}
+	return file_payment_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CancelPaymentRequest) GetPaymentIdentifier() isCancelPaymentRequest_PaymentIdentifier {
@@ -341,107 +631,1224 @@ func (*CancelPaymentRequest_PaymentId) isCancelPaymentRequest_PaymentIdentifier(
 
 func (*CancelPaymentRequest_OrderCode) isCancelPaymentRequest_PaymentIdentifier() {}
 
-var File_payment_proto protoreflect.FileDescriptor
+// Bank statement reconciliation
+type ImportBankStatementRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"` // camt053, mt940 or csv
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_payment_proto_rawDesc = "" +
-	"\n" +
-	"\rpayment.proto\x12\apayment\x1a\x1bgoogle/protobuf/empty.proto\"\xb1\x02\n" +
-	"\vPaymentData\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
-	"\n" +
-	"order_code\x18\x02 \x01(\tR\torderCode\x12\x17\n" +
-	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12\x1a\n" +
-	"\bprovider\x18\x05 \x01(\tR\bprovider\x12)\n" +
-	"\x10provider_details\x18\x06 \x01(\tR\x0fproviderDetails\x12>\n" +
-	"\bmetadata\x18\a \x03(\v2\".payment.PaymentData.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb5\x02\n" +
-	"\x15ProcessPaymentRequest\x12\x1d\n" +
-	"\n" +
-	"order_code\x18\x01 \x01(\tR\torderCode\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
-	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1a\n" +
-	"\bprovider\x18\x04 \x01(\tR\bprovider\x12)\n" +
-	"\x10provider_details\x18\x05 \x01(\tR\x0fproviderDetails\x12H\n" +
-	"\bmetadata\x18\x06 \x03(\v2,.payment.ProcessPaymentRequest.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"i\n" +
-	"\x16ProcessPaymentResponse\x12.\n" +
-	"\apayment\x18\x01 \x01(\v2\x14.payment.PaymentDataR\apayment\x12\x1f\n" +
-	"\vpayment_url\x18\x02 \x01(\tR\n" +
-	"paymentUrl\"\x86\x01\n" +
-	"\x14CancelPaymentRequest\x12\x1f\n" +
-	"\n" +
-	"payment_id\x18\x01 \x01(\tH\x00R\tpaymentId\x12\x1f\n" +
-	"\n" +
-	"order_code\x18\x02 \x01(\tH\x00R\torderCode\x12\x16\n" +
-	"\x06reason\x18\x03 \x01(\tR\x06reasonB\x14\n" +
-	"\x12payment_identifier2\xaf\x01\n" +
-	"\x0ePaymentService\x12S\n" +
-	"\x0eProcessPayment\x12\x1e.payment.ProcessPaymentRequest\x1a\x1f.payment.ProcessPaymentResponse\"\x00\x12H\n" +
-	"\rCancelPayment\x12\x1d.payment.CancelPaymentRequest\x1a\x16.google.protobuf.Empty\"\x00BKZIgithub.com/vogiaan1904/e-commerce-grpc-nest-proto/protogen/golang/paymentb\x06proto3"
+func (x *ImportBankStatementRequest) Reset() {
+	*x = ImportBankStatementRequest{}
+	mi := &file_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-var (
-	file_payment_proto_rawDescOnce sync.Once
-	file_payment_proto_rawDescData []byte
-)
+func (x *ImportBankStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func file_payment_proto_rawDescGZIP() []byte {
-	file_payment_proto_rawDescOnce.Do(func() {
-		file_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_payment_proto_rawDesc), len(file_payment_proto_rawDesc)))
-	})
-	return file_payment_proto_rawDescData
+func (*ImportBankStatementRequest) ProtoMessage() {}
+
+func (x *ImportBankStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var file_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_payment_proto_goTypes = []any{
-	(*PaymentData)(nil),            // 0: payment.PaymentData
-	(*ProcessPaymentRequest)(nil),  // 1: payment.ProcessPaymentRequest
-	(*ProcessPaymentResponse)(nil), // 2: payment.ProcessPaymentResponse
-	(*CancelPaymentRequest)(nil),   // 3: payment.CancelPaymentRequest
-	nil,                            // 4: payment.PaymentData.MetadataEntry
-	nil,                            // 5: payment.ProcessPaymentRequest.MetadataEntry
-	(*emptypb.Empty)(nil),          // 6: google.protobuf.Empty
+// Deprecated: Use ImportBankStatementRequest.ProtoReflect.Descriptor instead.
+func (*ImportBankStatementRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{6}
 }
-var file_payment_proto_depIdxs = []int32{
-	4, // 0: payment.PaymentData.metadata:type_name -> payment.PaymentData.MetadataEntry
-	5, // 1: payment.ProcessPaymentRequest.metadata:type_name -> payment.ProcessPaymentRequest.MetadataEntry
-	0, // 2: payment.ProcessPaymentResponse.payment:type_name -> payment.PaymentData
-	1, // 3: payment.PaymentService.ProcessPayment:input_type -> payment.ProcessPaymentRequest
-	3, // 4: payment.PaymentService.CancelPayment:input_type -> payment.CancelPaymentRequest
-	2, // 5: payment.PaymentService.ProcessPayment:output_type -> payment.ProcessPaymentResponse
-	6, // 6: payment.PaymentService.CancelPayment:output_type -> google.protobuf.Empty
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+
+func (x *ImportBankStatementRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
 }
 
-func init() { file_payment_proto_init() }
-func file_payment_proto_init() {
-	if File_payment_proto != nil {
-		return
+func (x *ImportBankStatementRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
 	}
-	file_payment_proto_msgTypes[3].OneofWrappers = []any{
-		(*CancelPaymentRequest_PaymentId)(nil),
-		(*CancelPaymentRequest_OrderCode)(nil),
+	return nil
+}
+
+type ImportBankStatementResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Total               int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Completed           int32                  `protobuf:"varint,2,opt,name=completed,proto3" json:"completed,omitempty"`
+	Review              int32                  `protobuf:"varint,3,opt,name=review,proto3" json:"review,omitempty"`
+	Unmatched           int32                  `protobuf:"varint,4,opt,name=unmatched,proto3" json:"unmatched,omitempty"`
+	CompletedOrderCodes []string               `protobuf:"bytes,5,rep,name=completed_order_codes,json=completedOrderCodes,proto3" json:"completed_order_codes,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ImportBankStatementResponse) Reset() {
+	*x = ImportBankStatementResponse{}
+	mi := &file_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportBankStatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportBankStatementResponse) ProtoMessage() {}
+
+func (x *ImportBankStatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportBankStatementResponse.ProtoReflect.Descriptor instead.
+func (*ImportBankStatementResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ImportBankStatementResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ImportBankStatementResponse) GetCompleted() int32 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *ImportBankStatementResponse) GetReview() int32 {
+	if x != nil {
+		return x.Review
+	}
+	return 0
+}
+
+func (x *ImportBankStatementResponse) GetUnmatched() int32 {
+	if x != nil {
+		return x.Unmatched
+	}
+	return 0
+}
+
+func (x *ImportBankStatementResponse) GetCompletedOrderCodes() []string {
+	if x != nil {
+		return x.CompletedOrderCodes
+	}
+	return nil
+}
+
+// Cash on delivery
+type ConfirmCashCollectedRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	OrderCode        string                 `protobuf:"bytes,1,opt,name=order_code,json=orderCode,proto3" json:"order_code,omitempty"`
+	CollectedAmount  float64                `protobuf:"fixed64,2,opt,name=collected_amount,json=collectedAmount,proto3" json:"collected_amount,omitempty"`
+	CourierReference string                 `protobuf:"bytes,3,opt,name=courier_reference,json=courierReference,proto3" json:"courier_reference,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ConfirmCashCollectedRequest) Reset() {
+	*x = ConfirmCashCollectedRequest{}
+	mi := &file_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmCashCollectedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmCashCollectedRequest) ProtoMessage() {}
+
+func (x *ConfirmCashCollectedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmCashCollectedRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmCashCollectedRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ConfirmCashCollectedRequest) GetOrderCode() string {
+	if x != nil {
+		return x.OrderCode
+	}
+	return ""
+}
+
+func (x *ConfirmCashCollectedRequest) GetCollectedAmount() float64 {
+	if x != nil {
+		return x.CollectedAmount
+	}
+	return 0
+}
+
+func (x *ConfirmCashCollectedRequest) GetCourierReference() string {
+	if x != nil {
+		return x.CourierReference
+	}
+	return ""
+}
+
+type ReportDeliveryReturnedRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	OrderCode        string                 `protobuf:"bytes,1,opt,name=order_code,json=orderCode,proto3" json:"order_code,omitempty"`
+	CourierReference string                 `protobuf:"bytes,2,opt,name=courier_reference,json=courierReference,proto3" json:"courier_reference,omitempty"`
+	Reason           string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ReportDeliveryReturnedRequest) Reset() {
+	*x = ReportDeliveryReturnedRequest{}
+	mi := &file_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportDeliveryReturnedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportDeliveryReturnedRequest) ProtoMessage() {}
+
+func (x *ReportDeliveryReturnedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportDeliveryReturnedRequest.ProtoReflect.Descriptor instead.
+func (*ReportDeliveryReturnedRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ReportDeliveryReturnedRequest) GetOrderCode() string {
+	if x != nil {
+		return x.OrderCode
+	}
+	return ""
+}
+
+func (x *ReportDeliveryReturnedRequest) GetCourierReference() string {
+	if x != nil {
+		return x.CourierReference
+	}
+	return ""
+}
+
+func (x *ReportDeliveryReturnedRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Optional gateway capabilities
+type GetPaymentStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to PaymentIdentifier:
+	//
+	//	*GetPaymentStatusRequest_PaymentId
+	//	*GetPaymentStatusRequest_OrderCode
+	PaymentIdentifier isGetPaymentStatusRequest_PaymentIdentifier `protobuf_oneof:"payment_identifier"`
+	Refresh           bool                                        `protobuf:"varint,3,opt,name=refresh,proto3" json:"refresh,omitempty"` // ask the provider instead of returning the stored status
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetPaymentStatusRequest) Reset() {
+	*x = GetPaymentStatusRequest{}
+	mi := &file_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentStatusRequest) ProtoMessage() {}
+
+func (x *GetPaymentStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetPaymentStatusRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetPaymentStatusRequest) GetPaymentIdentifier() isGetPaymentStatusRequest_PaymentIdentifier {
+	if x != nil {
+		return x.PaymentIdentifier
+	}
+	return nil
+}
+
+func (x *GetPaymentStatusRequest) GetPaymentId() string {
+	if x != nil {
+		if x, ok := x.PaymentIdentifier.(*GetPaymentStatusRequest_PaymentId); ok {
+			return x.PaymentId
+		}
+	}
+	return ""
+}
+
+func (x *GetPaymentStatusRequest) GetOrderCode() string {
+	if x != nil {
+		if x, ok := x.PaymentIdentifier.(*GetPaymentStatusRequest_OrderCode); ok {
+			return x.OrderCode
+		}
+	}
+	return ""
+}
+
+func (x *GetPaymentStatusRequest) GetRefresh() bool {
+	if x != nil {
+		return x.Refresh
+	}
+	return false
+}
+
+type isGetPaymentStatusRequest_PaymentIdentifier interface {
+	isGetPaymentStatusRequest_PaymentIdentifier()
+}
+
+type GetPaymentStatusRequest_PaymentId struct {
+	PaymentId string `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3,oneof"`
+}
+
+type GetPaymentStatusRequest_OrderCode struct {
+	OrderCode string `protobuf:"bytes,2,opt,name=order_code,json=orderCode,proto3,oneof"`
+}
+
+func (*GetPaymentStatusRequest_PaymentId) isGetPaymentStatusRequest_PaymentIdentifier() {}
+
+func (*GetPaymentStatusRequest_OrderCode) isGetPaymentStatusRequest_PaymentIdentifier() {}
+
+type GetPaymentStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *PaymentData           `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaymentStatusResponse) Reset() {
+	*x = GetPaymentStatusResponse{}
+	mi := &file_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentStatusResponse) ProtoMessage() {}
+
+func (x *GetPaymentStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetPaymentStatusResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetPaymentStatusResponse) GetPayment() *PaymentData {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+type RefundPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderCode     string                 `protobuf:"bytes,1,opt,name=order_code,json=orderCode,proto3" json:"order_code,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"` // 0 refunds the full amount
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
+	mi := &file_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentRequest) ProtoMessage() {}
+
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RefundPaymentRequest) GetOrderCode() string {
+	if x != nil {
+		return x.OrderCode
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundPaymentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RefundPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefundId      string                 `protobuf:"bytes,1,opt,name=refund_id,json=refundId,proto3" json:"refund_id,omitempty"`
+	Status        PaymentStatus          `protobuf:"varint,2,opt,name=status,proto3,enum=payment.PaymentStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentResponse) Reset() {
+	*x = RefundPaymentResponse{}
+	mi := &file_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentResponse) ProtoMessage() {}
+
+func (x *RefundPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentResponse.ProtoReflect.Descriptor instead.
+func (*RefundPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RefundPaymentResponse) GetRefundId() string {
+	if x != nil {
+		return x.RefundId
+	}
+	return ""
+}
+
+func (x *RefundPaymentResponse) GetStatus() PaymentStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+type CapturePaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderCode     string                 `protobuf:"bytes,1,opt,name=order_code,json=orderCode,proto3" json:"order_code,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"` // 0 captures the full amount
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CapturePaymentRequest) Reset() {
+	*x = CapturePaymentRequest{}
+	mi := &file_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapturePaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapturePaymentRequest) ProtoMessage() {}
+
+func (x *CapturePaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapturePaymentRequest.ProtoReflect.Descriptor instead.
+func (*CapturePaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CapturePaymentRequest) GetOrderCode() string {
+	if x != nil {
+		return x.OrderCode
+	}
+	return ""
+}
+
+func (x *CapturePaymentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type CreatePaymentTokenRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UserId          string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Provider        string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	ProviderDetails string                 `protobuf:"bytes,3,opt,name=provider_details,json=providerDetails,proto3" json:"provider_details,omitempty"`
+	Metadata        map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreatePaymentTokenRequest) Reset() {
+	*x = CreatePaymentTokenRequest{}
+	mi := &file_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePaymentTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePaymentTokenRequest) ProtoMessage() {}
+
+func (x *CreatePaymentTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePaymentTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreatePaymentTokenRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CreatePaymentTokenRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreatePaymentTokenRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *CreatePaymentTokenRequest) GetProviderDetails() string {
+	if x != nil {
+		return x.ProviderDetails
+	}
+	return ""
+}
+
+func (x *CreatePaymentTokenRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type CreatePaymentTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	RedirectUrl   string                 `protobuf:"bytes,2,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePaymentTokenResponse) Reset() {
+	*x = CreatePaymentTokenResponse{}
+	mi := &file_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePaymentTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePaymentTokenResponse) ProtoMessage() {}
+
+func (x *CreatePaymentTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePaymentTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreatePaymentTokenResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreatePaymentTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreatePaymentTokenResponse) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+type ProviderInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,2,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Environment   string                 `protobuf:"bytes,3,opt,name=environment,proto3" json:"environment,omitempty"` // sandbox or production, empty when the provider has neither
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderInfo) Reset() {
+	*x = ProviderInfo{}
+	mi := &file_payment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderInfo) ProtoMessage() {}
+
+func (x *ProviderInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderInfo.ProtoReflect.Descriptor instead.
+func (*ProviderInfo) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ProviderInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProviderInfo) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *ProviderInfo) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+type ListProvidersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Providers     []*ProviderInfo        `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProvidersResponse) Reset() {
+	*x = ListProvidersResponse{}
+	mi := &file_payment_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProvidersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProvidersResponse) ProtoMessage() {}
+
+func (x *ListProvidersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProvidersResponse.ProtoReflect.Descriptor instead.
+func (*ListProvidersResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListProvidersResponse) GetProviders() []*ProviderInfo {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type ListBanksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"` // 0 lists every bank, otherwise only banks accepting the amount
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBanksRequest) Reset() {
+	*x = ListBanksRequest{}
+	mi := &file_payment_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBanksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBanksRequest) ProtoMessage() {}
+
+func (x *ListBanksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBanksRequest.ProtoReflect.Descriptor instead.
+func (*ListBanksRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListBanksRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ListBanksRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type Bank struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"` // value to pass as provider_details
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Channel       string                 `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	MinAmount     float64                `protobuf:"fixed64,4,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	MaxAmount     float64                `protobuf:"fixed64,5,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`
+	DisplayOrder  int32                  `protobuf:"varint,6,opt,name=display_order,json=displayOrder,proto3" json:"display_order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bank) Reset() {
+	*x = Bank{}
+	mi := &file_payment_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bank) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bank) ProtoMessage() {}
+
+func (x *Bank) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bank.ProtoReflect.Descriptor instead.
+func (*Bank) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Bank) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Bank) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Bank) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *Bank) GetMinAmount() float64 {
+	if x != nil {
+		return x.MinAmount
+	}
+	return 0
+}
+
+func (x *Bank) GetMaxAmount() float64 {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return 0
+}
+
+func (x *Bank) GetDisplayOrder() int32 {
+	if x != nil {
+		return x.DisplayOrder
+	}
+	return 0
+}
+
+type ListBanksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Banks         []*Bank                `protobuf:"bytes,1,rep,name=banks,proto3" json:"banks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBanksResponse) Reset() {
+	*x = ListBanksResponse{}
+	mi := &file_payment_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBanksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBanksResponse) ProtoMessage() {}
+
+func (x *ListBanksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBanksResponse.ProtoReflect.Descriptor instead.
+func (*ListBanksResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListBanksResponse) GetBanks() []*Bank {
+	if x != nil {
+		return x.Banks
+	}
+	return nil
+}
+
+var File_payment_proto protoreflect.FileDescriptor
+
+const file_payment_proto_rawDesc = "" +
+	"\n" +
+	"\rpayment.proto\x12\apayment\x1a\x1bgoogle/protobuf/empty.proto\"\xe1\x02\n" +
+	"\vPaymentData\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"order_code\x18\x02 \x01(\tR\torderCode\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\bprovider\x18\x05 \x01(\tR\bprovider\x12)\n" +
+	"\x10provider_details\x18\x06 \x01(\tR\x0fproviderDetails\x12>\n" +
+	"\bmetadata\x18\a \x03(\v2\".payment.PaymentData.MetadataEntryR\bmetadata\x12.\n" +
+	"\x06status\x18\b \x01(\x0e2\x16.payment.PaymentStatusR\x06status\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9e\x03\n" +
+	"\x15ProcessPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"order_code\x18\x01 \x01(\tR\torderCode\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\bprovider\x18\x04 \x01(\tR\bprovider\x12)\n" +
+	"\x10provider_details\x18\x05 \x01(\tR\x0fproviderDetails\x12H\n" +
+	"\bmetadata\x18\x06 \x03(\v2,.payment.ProcessPaymentRequest.MetadataEntryR\bmetadata\x12\x1a\n" +
+	"\bcurrency\x18\a \x01(\tR\bcurrency\x12!\n" +
+	"\fuser_segment\x18\b \x01(\tR\vuserSegment\x12(\n" +
+	"\x04mode\x18\t \x01(\x0e2\x14.payment.PaymentModeR\x04mode\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xed\x01\n" +
+	"\x16ProcessPaymentResponse\x12.\n" +
+	"\apayment\x18\x01 \x01(\v2\x14.payment.PaymentDataR\apayment\x12\x1f\n" +
+	"\vpayment_url\x18\x02 \x01(\tR\n" +
+	"paymentUrl\x12(\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x14.payment.PaymentModeR\x04mode\x12$\n" +
+	"\x02qr\x18\x04 \x01(\v2\x12.payment.QRPayloadH\x00R\x02qr\x12'\n" +
+	"\x03app\x18\x05 \x01(\v2\x13.payment.AppPayloadH\x00R\x03appB\t\n" +
+	"\apayload\"$\n" +
+	"\tQRPayload\x12\x17\n" +
+	"\aqr_code\x18\x01 \x01(\tR\x06qrCode\">\n" +
+	"\n" +
+	"AppPayload\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1a\n" +
+	"\bdeeplink\x18\x02 \x01(\tR\bdeeplink\"\x86\x01\n" +
+	"\x14CancelPaymentRequest\x12\x1f\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tH\x00R\tpaymentId\x12\x1f\n" +
+	"\n" +
+	"order_code\x18\x02 \x01(\tH\x00R\torderCode\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reasonB\x14\n" +
+	"\x12payment_identifier\"N\n" +
+	"\x1aImportBankStatementRequest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"\xbb\x01\n" +
+	"\x1bImportBankStatementResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x1c\n" +
+	"\tcompleted\x18\x02 \x01(\x05R\tcompleted\x12\x16\n" +
+	"\x06review\x18\x03 \x01(\x05R\x06review\x12\x1c\n" +
+	"\tunmatched\x18\x04 \x01(\x05R\tunmatched\x122\n" +
+	"\x15completed_order_codes\x18\x05 \x03(\tR\x13completedOrderCodes\"\x94\x01\n" +
+	"\x1bConfirmCashCollectedRequest\x12\x1d\n" +
+	"\n" +
+	"order_code\x18\x01 \x01(\tR\torderCode\x12)\n" +
+	"\x10collected_amount\x18\x02 \x01(\x01R\x0fcollectedAmount\x12+\n" +
+	"\x11courier_reference\x18\x03 \x01(\tR\x10courierReference\"\x83\x01\n" +
+	"\x1dReportDeliveryReturnedRequest\x12\x1d\n" +
+	"\n" +
+	"order_code\x18\x01 \x01(\tR\torderCode\x12+\n" +
+	"\x11courier_reference\x18\x02 \x01(\tR\x10courierReference\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x8b\x01\n" +
+	"\x17GetPaymentStatusRequest\x12\x1f\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tH\x00R\tpaymentId\x12\x1f\n" +
+	"\n" +
+	"order_code\x18\x02 \x01(\tH\x00R\torderCode\x12\x18\n" +
+	"\arefresh\x18\x03 \x01(\bR\arefreshB\x14\n" +
+	"\x12payment_identifier\"J\n" +
+	"\x18GetPaymentStatusResponse\x12.\n" +
+	"\apayment\x18\x01 \x01(\v2\x14.payment.PaymentDataR\apayment\"e\n" +
+	"\x14RefundPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"order_code\x18\x01 \x01(\tR\torderCode\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"d\n" +
+	"\x15RefundPaymentResponse\x12\x1b\n" +
+	"\trefund_id\x18\x01 \x01(\tR\brefundId\x12.\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x16.payment.PaymentStatusR\x06status\"N\n" +
+	"\x15CapturePaymentRequest\x12\x1d\n" +
+	"\n" +
+	"order_code\x18\x01 \x01(\tR\torderCode\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\"\x86\x02\n" +
+	"\x19CreatePaymentTokenRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12)\n" +
+	"\x10provider_details\x18\x03 \x01(\tR\x0fproviderDetails\x12L\n" +
+	"\bmetadata\x18\x04 \x03(\v20.payment.CreatePaymentTokenRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"U\n" +
+	"\x1aCreatePaymentTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12!\n" +
+	"\fredirect_url\x18\x02 \x01(\tR\vredirectUrl\"h\n" +
+	"\fProviderInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\"\n" +
+	"\fcapabilities\x18\x02 \x03(\tR\fcapabilities\x12 \n" +
+	"\venvironment\x18\x03 \x01(\tR\venvironment\"L\n" +
+	"\x15ListProvidersResponse\x123\n" +
+	"\tproviders\x18\x01 \x03(\v2\x15.payment.ProviderInfoR\tproviders\"F\n" +
+	"\x10ListBanksRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\"\xab\x01\n" +
+	"\x04Bank\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x1d\n" +
+	"\n" +
+	"min_amount\x18\x04 \x01(\x01R\tminAmount\x12\x1d\n" +
+	"\n" +
+	"max_amount\x18\x05 \x01(\x01R\tmaxAmount\x12#\n" +
+	"\rdisplay_order\x18\x06 \x01(\x05R\fdisplayOrder\"8\n" +
+	"\x11ListBanksResponse\x12#\n" +
+	"\x05banks\x18\x01 \x03(\v2\r.payment.BankR\x05banks*\xbf\x01\n" +
+	"\rPaymentStatus\x12\x1e\n" +
+	"\x1aPAYMENT_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16PAYMENT_STATUS_PENDING\x10\x01\x12\x1c\n" +
+	"\x18PAYMENT_STATUS_COMPLETED\x10\x02\x12\x19\n" +
+	"\x15PAYMENT_STATUS_FAILED\x10\x03\x12\x1c\n" +
+	"\x18PAYMENT_STATUS_CANCELLED\x10\x04\x12\x1b\n" +
+	"\x17PAYMENT_STATUS_REFUNDED\x10\x05*S\n" +
+	"\vPaymentMode\x12\x19\n" +
+	"\x15PAYMENT_MODE_REDIRECT\x10\x00\x12\x13\n" +
+	"\x0fPAYMENT_MODE_QR\x10\x01\x12\x14\n" +
+	"\x10PAYMENT_MODE_APP\x10\x022\xb2\a\n" +
+	"\x0ePaymentService\x12S\n" +
+	"\x0eProcessPayment\x12\x1e.payment.ProcessPaymentRequest\x1a\x1f.payment.ProcessPaymentResponse\"\x00\x12H\n" +
+	"\rCancelPayment\x12\x1d.payment.CancelPaymentRequest\x1a\x16.google.protobuf.Empty\"\x00\x12b\n" +
+	"\x13ImportBankStatement\x12#.payment.ImportBankStatementRequest\x1a$.payment.ImportBankStatementResponse\"\x00\x12V\n" +
+	"\x14ConfirmCashCollected\x12$.payment.ConfirmCashCollectedRequest\x1a\x16.google.protobuf.Empty\"\x00\x12Z\n" +
+	"\x16ReportDeliveryReturned\x12&.payment.ReportDeliveryReturnedRequest\x1a\x16.google.protobuf.Empty\"\x00\x12Y\n" +
+	"\x10GetPaymentStatus\x12 .payment.GetPaymentStatusRequest\x1a!.payment.GetPaymentStatusResponse\"\x00\x12P\n" +
+	"\rRefundPayment\x12\x1d.payment.RefundPaymentRequest\x1a\x1e.payment.RefundPaymentResponse\"\x00\x12J\n" +
+	"\x0eCapturePayment\x12\x1e.payment.CapturePaymentRequest\x1a\x16.google.protobuf.Empty\"\x00\x12_\n" +
+	"\x12CreatePaymentToken\x12\".payment.CreatePaymentTokenRequest\x1a#.payment.CreatePaymentTokenResponse\"\x00\x12I\n" +
+	"\rListProviders\x12\x16.google.protobuf.Empty\x1a\x1e.payment.ListProvidersResponse\"\x00\x12D\n" +
+	"\tListBanks\x12\x19.payment.ListBanksRequest\x1a\x1a.payment.ListBanksResponse\"\x00BKZIgithub.com/vogiaan1904/e-commerce-grpc-nest-proto/protogen/golang/paymentb\x06proto3"
+
+var (
+	file_payment_proto_rawDescOnce sync.Once
+	file_payment_proto_rawDescData []byte
+)
+
+func file_payment_proto_rawDescGZIP() []byte {
+	file_payment_proto_rawDescOnce.Do(func() {
+		file_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_payment_proto_rawDesc), len(file_payment_proto_rawDesc)))
+	})
+	return file_payment_proto_rawDescData
+}
+
+var file_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_payment_proto_goTypes = []any{
+	(PaymentStatus)(0),                    // 0: payment.PaymentStatus
+	(PaymentMode)(0),                      // 1: payment.PaymentMode
+	(*PaymentData)(nil),                   // 2: payment.PaymentData
+	(*ProcessPaymentRequest)(nil),         // 3: payment.ProcessPaymentRequest
+	(*ProcessPaymentResponse)(nil),        // 4: payment.ProcessPaymentResponse
+	(*QRPayload)(nil),                     // 5: payment.QRPayload
+	(*AppPayload)(nil),                    // 6: payment.AppPayload
+	(*CancelPaymentRequest)(nil),          // 7: payment.CancelPaymentRequest
+	(*ImportBankStatementRequest)(nil),    // 8: payment.ImportBankStatementRequest
+	(*ImportBankStatementResponse)(nil),   // 9: payment.ImportBankStatementResponse
+	(*ConfirmCashCollectedRequest)(nil),   // 10: payment.ConfirmCashCollectedRequest
+	(*ReportDeliveryReturnedRequest)(nil), // 11: payment.ReportDeliveryReturnedRequest
+	(*GetPaymentStatusRequest)(nil),       // 12: payment.GetPaymentStatusRequest
+	(*GetPaymentStatusResponse)(nil),      // 13: payment.GetPaymentStatusResponse
+	(*RefundPaymentRequest)(nil),          // 14: payment.RefundPaymentRequest
+	(*RefundPaymentResponse)(nil),         // 15: payment.RefundPaymentResponse
+	(*CapturePaymentRequest)(nil),         // 16: payment.CapturePaymentRequest
+	(*CreatePaymentTokenRequest)(nil),     // 17: payment.CreatePaymentTokenRequest
+	(*CreatePaymentTokenResponse)(nil),    // 18: payment.CreatePaymentTokenResponse
+	(*ProviderInfo)(nil),                  // 19: payment.ProviderInfo
+	(*ListProvidersResponse)(nil),         // 20: payment.ListProvidersResponse
+	(*ListBanksRequest)(nil),              // 21: payment.ListBanksRequest
+	(*Bank)(nil),                          // 22: payment.Bank
+	(*ListBanksResponse)(nil),             // 23: payment.ListBanksResponse
+	nil,                                   // 24: payment.PaymentData.MetadataEntry
+	nil,                                   // 25: payment.ProcessPaymentRequest.MetadataEntry
+	nil,                                   // 26: payment.CreatePaymentTokenRequest.MetadataEntry
+	(*emptypb.Empty)(nil),                 // 27: google.protobuf.Empty
+}
+var file_payment_proto_depIdxs = []int32{
+	24, // 0: payment.PaymentData.metadata:type_name -> payment.PaymentData.MetadataEntry
+	0,  // 1: payment.PaymentData.status:type_name -> payment.PaymentStatus
+	25, // 2: payment.ProcessPaymentRequest.metadata:type_name -> payment.ProcessPaymentRequest.MetadataEntry
+	1,  // 3: payment.ProcessPaymentRequest.mode:type_name -> payment.PaymentMode
+	2,  // 4: payment.ProcessPaymentResponse.payment:type_name -> payment.PaymentData
+	1,  // 5: payment.ProcessPaymentResponse.mode:type_name -> payment.PaymentMode
+	5,  // 6: payment.ProcessPaymentResponse.qr:type_name -> payment.QRPayload
+	6,  // 7: payment.ProcessPaymentResponse.app:type_name -> payment.AppPayload
+	2,  // 8: payment.GetPaymentStatusResponse.payment:type_name -> payment.PaymentData
+	0,  // 9: payment.RefundPaymentResponse.status:type_name -> payment.PaymentStatus
+	26, // 10: payment.CreatePaymentTokenRequest.metadata:type_name -> payment.CreatePaymentTokenRequest.MetadataEntry
+	19, // 11: payment.ListProvidersResponse.providers:type_name -> payment.ProviderInfo
+	22, // 12: payment.ListBanksResponse.banks:type_name -> payment.Bank
+	3,  // 13: payment.PaymentService.ProcessPayment:input_type -> payment.ProcessPaymentRequest
+	7,  // 14: payment.PaymentService.CancelPayment:input_type -> payment.CancelPaymentRequest
+	8,  // 15: payment.PaymentService.ImportBankStatement:input_type -> payment.ImportBankStatementRequest
+	10, // 16: payment.PaymentService.ConfirmCashCollected:input_type -> payment.ConfirmCashCollectedRequest
+	11, // 17: payment.PaymentService.ReportDeliveryReturned:input_type -> payment.ReportDeliveryReturnedRequest
+	12, // 18: payment.PaymentService.GetPaymentStatus:input_type -> payment.GetPaymentStatusRequest
+	14, // 19: payment.PaymentService.RefundPayment:input_type -> payment.RefundPaymentRequest
+	16, // 20: payment.PaymentService.CapturePayment:input_type -> payment.CapturePaymentRequest
+	17, // 21: payment.PaymentService.CreatePaymentToken:input_type -> payment.CreatePaymentTokenRequest
+	27, // 22: payment.PaymentService.ListProviders:input_type -> google.protobuf.Empty
+	21, // 23: payment.PaymentService.ListBanks:input_type -> payment.ListBanksRequest
+	4,  // 24: payment.PaymentService.ProcessPayment:output_type -> payment.ProcessPaymentResponse
+	27, // 25: payment.PaymentService.CancelPayment:output_type -> google.protobuf.Empty
+	9,  // 26: payment.PaymentService.ImportBankStatement:output_type -> payment.ImportBankStatementResponse
+	27, // 27: payment.PaymentService.ConfirmCashCollected:output_type -> google.protobuf.Empty
+	27, // 28: payment.PaymentService.ReportDeliveryReturned:output_type -> google.protobuf.Empty
+	13, // 29: payment.PaymentService.GetPaymentStatus:output_type -> payment.GetPaymentStatusResponse
+	15, // 30: payment.PaymentService.RefundPayment:output_type -> payment.RefundPaymentResponse
+	27, // 31: payment.PaymentService.CapturePayment:output_type -> google.protobuf.Empty
+	18, // 32: payment.PaymentService.CreatePaymentToken:output_type -> payment.CreatePaymentTokenResponse
+	20, // 33: payment.PaymentService.ListProviders:output_type -> payment.ListProvidersResponse
+	23, // 34: payment.PaymentService.ListBanks:output_type -> payment.ListBanksResponse
+	24, // [24:35] is the sub-list for method output_type
+	13, // [13:24] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_payment_proto_init() }
+func file_payment_proto_init() {
+	if File_payment_proto != nil {
+		return
+	}
+	file_payment_proto_msgTypes[2].OneofWrappers = []any{
+		(*ProcessPaymentResponse_Qr)(nil),
+		(*ProcessPaymentResponse_App)(nil),
+	}
+	file_payment_proto_msgTypes[5].OneofWrappers = []any{
+		(*CancelPaymentRequest_PaymentId)(nil),
+		(*CancelPaymentRequest_OrderCode)(nil),
+	}
+	file_payment_proto_msgTypes[10].OneofWrappers = []any{
+		(*GetPaymentStatusRequest_PaymentId)(nil),
+		(*GetPaymentStatusRequest_OrderCode)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payment_proto_rawDesc), len(file_payment_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      2,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_payment_proto_goTypes,
 		DependencyIndexes: file_payment_proto_depIdxs,
+		EnumInfos:         file_payment_proto_enumTypes,
 		MessageInfos:      file_payment_proto_msgTypes,
 	}.Build()
 	File_payment_proto = out.File
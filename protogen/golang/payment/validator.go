@@ -31,8 +31,109 @@ func (r *ProcessPaymentRequest) Validate() error {
 		log.Printf("User ID is required")
 		return ErrRequiredField
 	}
-	if r.Provider != string(models.GatewayTypeZalopay) {
-		log.Printf("Invalid provider")
+	if _, ok := PaymentMode_name[int32(r.Mode)]; !ok {
+		log.Printf("Invalid payment mode")
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+func (r *ImportBankStatementRequest) Validate() error {
+	if len(r.Content) == 0 {
+		log.Printf("Statement content is required")
+		return ErrRequiredField
+	}
+	switch models.StatementFormat(r.Format) {
+	case models.StatementFormatCAMT053, models.StatementFormatMT940, models.StatementFormatCSV:
+	default:
+		log.Printf("Invalid statement format")
+		return ErrInvalidInput
+	}
+
+	return nil
+}
+
+func (r *ConfirmCashCollectedRequest) Validate() error {
+	if r.OrderCode == "" {
+		log.Printf("Order code is required")
+		return ErrRequiredField
+	}
+	if r.CollectedAmount <= 0 {
+		log.Printf("Invalid collected amount")
+		return ErrInvalidInput
+	}
+	if r.CourierReference == "" {
+		log.Printf("Courier reference is required")
+		return ErrRequiredField
+	}
+
+	return nil
+}
+
+func (r *ReportDeliveryReturnedRequest) Validate() error {
+	if r.OrderCode == "" {
+		log.Printf("Order code is required")
+		return ErrRequiredField
+	}
+
+	return nil
+}
+
+func (r *GetPaymentStatusRequest) Validate() error {
+	if r.GetPaymentId() == "" && r.GetOrderCode() == "" {
+		log.Printf("Payment ID or order code is required")
+		return ErrRequiredField
+	}
+
+	return nil
+}
+
+func (r *RefundPaymentRequest) Validate() error {
+	if r.OrderCode == "" {
+		log.Printf("Order code is required")
+		return ErrRequiredField
+	}
+	if r.Amount < 0 {
+		log.Printf("Invalid refund amount")
+		return ErrInvalidInput
+	}
+
+	return nil
+}
+
+func (r *CapturePaymentRequest) Validate() error {
+	if r.OrderCode == "" {
+		log.Printf("Order code is required")
+		return ErrRequiredField
+	}
+	if r.Amount < 0 {
+		log.Printf("Invalid capture amount")
+		return ErrInvalidInput
+	}
+
+	return nil
+}
+
+func (r *CreatePaymentTokenRequest) Validate() error {
+	if r.UserId == "" {
+		log.Printf("User ID is required")
+		return ErrRequiredField
+	}
+	if r.Provider == "" {
+		log.Printf("Provider is required")
+		return ErrRequiredField
+	}
+
+	return nil
+}
+
+func (r *ListBanksRequest) Validate() error {
+	if r.Provider == "" {
+		log.Printf("Provider is required")
+		return ErrRequiredField
+	}
+	if r.Amount < 0 {
+		log.Printf("Invalid amount")
 		return ErrInvalidInput
 	}
 
@@ -0,0 +1,32 @@
+#!/bin/bash
+
+# Script to register the payment search attributes in the Temporal namespace.
+# Run it once per namespace before setting TEMPORAL_SEARCH_ATTRIBUTES=true.
+
+NAMESPACE="${TEMPORAL_NAMESPACE:-default}"
+ADDRESS="${TEMPORAL_HOST_PORT:-localhost:7233}"
+
+register() {
+  local name="$1" type="$2"
+
+  if temporal operator search-attribute list --namespace "$NAMESPACE" --address "$ADDRESS" | grep -qw "$name"; then
+    echo "$name already registered."
+    return
+  fi
+
+  echo "Registering $name ($type)..."
+  temporal operator search-attribute create --namespace "$NAMESPACE" --address "$ADDRESS" \
+    --name "$name" --type "$type" || exit 1
+}
+
+main() {
+  register OrderCode Keyword
+  register PaymentID Keyword
+  register Provider Keyword
+  register Amount Double
+  register PaymentStatus Keyword
+
+  echo "Search attributes registered in namespace $NAMESPACE."
+}
+
+main